@@ -24,6 +24,28 @@ Classification:
 * [LogLoss](https://en.wikipedia.org/wiki/Loss_functions_for_classification)
 * [Precision](https://en.wikipedia.org/wiki/Information_retrieval#Precision)
 * [Sensitivity](https://en.wikipedia.org/wiki/Sensitivity_(test))
+* Cost Matrix
+
+Scoring:
+
+* [Lift](https://en.wikipedia.org/wiki/Lift_(data_mining)) and Cumulative Gains
+* [Kolmogorov-Smirnov](https://en.wikipedia.org/wiki/Kolmogorov%E2%80%93Smirnov_test)
+* [AUC](https://en.wikipedia.org/wiki/Receiver_operating_characteristic#Area_under_the_curve)
+* [Gini](https://en.wikipedia.org/wiki/Gini_coefficient)
+
+Multilabel classification:
+
+* [Hamming Loss](https://en.wikipedia.org/wiki/Multi-label_classification#Statistics_and_evaluation_metrics)
+* Subset Accuracy
+* [Jaccard Index](https://en.wikipedia.org/wiki/Jaccard_index)
+* Micro/Macro F1
+
+Fairness:
+
+* Demographic Parity (difference and ratio)
+* Equal Opportunity and Equalized Odds
+* Predictive Parity
+* [Disparate Impact](https://en.wikipedia.org/wiki/Disparate_impact)
 
 Regression:
 
@@ -32,11 +54,77 @@ Regression:
 * [Root Mean Squared Error](https://en.wikipedia.org/wiki/Root-mean-square_deviation)
 * [Mean Squared Error](https://en.wikipedia.org/wiki/Root-mean-square_deviation)
 * [R²](https://en.wikipedia.org/wiki/Coefficient_of_determination)
+* [Pearson Correlation](https://en.wikipedia.org/wiki/Pearson_correlation_coefficient)
+* [Spearman Correlation](https://en.wikipedia.org/wiki/Spearman%27s_rank_correlation_coefficient)
+* [Kendall Correlation](https://en.wikipedia.org/wiki/Kendall_rank_correlation_coefficient)
+* [Tweedie Deviance](https://en.wikipedia.org/wiki/Tweedie_distribution#The_Tweedie_deviance) (incl. Poisson and Gamma)
+* [Huber Loss](https://en.wikipedia.org/wiki/Huber_loss)
+* [Quantile Loss](https://en.wikipedia.org/wiki/Quantile_regression)
+* [Interval Coverage](https://en.wikipedia.org/wiki/Prediction_interval)
+
+Clustering:
+
+* [Adjusted Rand Index](https://en.wikipedia.org/wiki/Rand_index#Adjusted_Rand_index)
+* [Mutual Information](https://en.wikipedia.org/wiki/Mutual_information) (incl. normalized and adjusted)
+* [Homogeneity, Completeness and V-Measure](https://www.aclweb.org/anthology/D07-1043.pdf)
+* [Fowlkes-Mallows Index](https://en.wikipedia.org/wiki/Fowlkes%E2%80%93Mallows_index)
+* [Silhouette](https://en.wikipedia.org/wiki/Silhouette_(clustering))
+* [Davies-Bouldin Index](https://en.wikipedia.org/wiki/Davies%E2%80%93Bouldin_index)
+* [Calinski-Harabasz Index](https://en.wikipedia.org/wiki/Calinski%E2%80%93Harabasz_index)
+
+Ranking:
+
+* [NDCG](https://en.wikipedia.org/wiki/Discounted_cumulative_gain)
+* [Mean Average Precision](https://en.wikipedia.org/wiki/Evaluation_measures_(information_retrieval)#Mean_average_precision)
+* [Mean Reciprocal Rank](https://en.wikipedia.org/wiki/Mean_reciprocal_rank)
+* [Precision/Recall at K](https://en.wikipedia.org/wiki/Evaluation_measures_(information_retrieval)#Precision_at_k)
+* Hit Rate
+
+Recommendation:
+
+* [Catalog Coverage](https://en.wikipedia.org/wiki/Recommender_system#Beyond_accuracy)
+* Novelty
+* Intra-List Diversity
+* Personalization
+
+Drift (see [drift](https://godoc.org/github.com/bsm/mlmetrics/drift)):
+
+* Population Stability Index
+* [Kullback-Leibler Divergence](https://en.wikipedia.org/wiki/Kullback%E2%80%93Leibler_divergence)
+* [Jensen-Shannon Divergence](https://en.wikipedia.org/wiki/Jensen%E2%80%93Shannon_divergence)
+* [Wasserstein Distance](https://en.wikipedia.org/wiki/Wasserstein_metric)
+* [Kolmogorov-Smirnov Test](https://en.wikipedia.org/wiki/Kolmogorov%E2%80%93Smirnov_test)
+* Concept Drift Detection (DDM, EDDM, ADWIN, Page-Hinkley)
+
+## Invalid Observations
+
+Observations with invalid inputs, such as negative categories, NaN values, probabilities outside `[0, 1]` or
+non-positive weights, are not recorded. Metrics count them by reason, see `Rejected()`. Use `ObserveWeightStrict` to
+receive an error for each rejected observation. `ObserveBatch` returns a `*BatchError` if rows of a batch were rejected
+and `ErrLengthMismatch`, counted as a rejection of the whole batch, if the lengths of its inputs differ. Snapshots
+include the rejection counts, which are reset by `Reset` and `SnapshotAndReset`.
+
+## Changes
+
+* The R² score of `Regression` and `MultiRegression` now uses an exact weighted update of the total sum of squares,
+  which is consistent with merging observations across shards. Previously the total sum of squares was overestimated,
+  so R² values are lower than in earlier releases and may be negative for poor models.
 
 ## Documentation
 
 Documentation and example are available via godoc at http://godoc.org/github.com/bsm/mlmetrics
 
+## Command-line
+
+The `mlmetrics` command evaluates predictions stored in CSV or JSONL files:
+
+```shell
+go install github.com/bsm/mlmetrics/cmd/mlmetrics@latest
+mlmetrics -task classification -score prob -group country predictions.csv
+```
+
+Run `mlmetrics -h` for all options.
+
 ## Example
 
 ```go
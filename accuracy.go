@@ -1,16 +1,19 @@
 package mlmetrics
 
-import (
-	"sync"
-)
-
 // Accuracy is a basic classification metric. It measures how often the
 // classifier makes the correct prediction. It is the ratio between the
 // weight of correct predictions and the total weight of predictions.
 type Accuracy struct {
-	observed float64
-	correct  float64
-	mu       sync.RWMutex
+	rejector
+	shards shards[accuracyState]
+}
+
+// AccuracySnapshot is a point-in-time snapshot of an Accuracy metric.
+type AccuracySnapshot struct {
+	Weight   float64    // total weight observed
+	Correct  float64    // weight of correct observations
+	Rate     float64    // rate of correct predictions
+	Rejected Rejections // observations rejected by validation
 }
 
 // NewAccuracy inits a new metric.
@@ -20,10 +23,8 @@ func NewAccuracy() *Accuracy {
 
 // Reset resets state.
 func (m *Accuracy) Reset() {
-	m.mu.Lock()
-	m.observed = 0
-	m.correct = 0
-	m.mu.Unlock()
+	m.shards.each(func(s *accuracyState) { *s = accuracyState{} })
+	m.resetRejected()
 }
 
 // Observe records an observation of the actual vs the predicted category.
@@ -33,45 +34,126 @@ func (m *Accuracy) Observe(actual, predicted int) {
 
 // ObserveWeight records an observation of the actual vs the predicted category with a given weight.
 func (m *Accuracy) ObserveWeight(actual, predicted int, weight float64) {
-	if !isValidCategory(actual) || !isValidCategory(predicted) || !isValidWeight(weight) {
-		return
+	_ = m.ObserveWeightStrict(actual, predicted, weight)
+}
+
+// ObserveWeightStrict is like ObserveWeight, but returns an error if the observation was
+// rejected by validation, see Rejected.
+func (m *Accuracy) ObserveWeightStrict(actual, predicted int, weight float64) error {
+	if err := validateCategories(actual, predicted, weight); err != nil {
+		return m.reject(err)
 	}
 
-	equal := predicted == actual
+	sh := m.shards.acquire()
+	sh.state.observe(actual, predicted, weight)
+	m.shards.release(sh)
+	return nil
+}
 
-	m.mu.Lock()
-	m.observed += weight
-	if equal {
-		m.correct += weight
+// ObserveBatch records a batch of observations of actual vs predicted categories.
+// Weights are optional, if given, they must match the number of observations.
+// Returns ErrLengthMismatch if the lengths of the inputs differ and a *BatchError
+// if observations were rejected.
+func (m *Accuracy) ObserveBatch(actual, predicted []int, weights []float64) error {
+	return ObserveAccuracyBatch(m, actual, predicted, weights)
+}
+
+// ObserveAccuracyBatch records a batch of observations of actual vs predicted categories
+// of any integer type without converting the inputs first. Categories which cannot be
+// represented as a non-negative int are rejected. See Accuracy.ObserveBatch.
+func ObserveAccuracyBatch[A, P Integer](m *Accuracy, actual []A, predicted []P, weights []float64) error {
+	if err := validateBatch(len(actual), weights, len(predicted)); err != nil {
+		return m.rejectBatch(len(actual), err)
+	}
+
+	var berr BatchError
+	sh := m.shards.acquire()
+	for i := range actual {
+		act, pred, weight := categoryOf(actual[i]), categoryOf(predicted[i]), batchWeight(weights, i)
+		if err := validateCategories(act, pred, weight); err != nil {
+			berr.add(i, m.reject(err))
+			continue
+		}
+		sh.state.observe(act, pred, weight)
 	}
-	m.mu.Unlock()
+	m.shards.release(sh)
+	return berr.errOrNil()
 }
 
 // TotalWeight returns the total weight observed.
 func (m *Accuracy) TotalWeight() float64 {
-	m.mu.RLock()
-	observed := m.observed
-	m.mu.RUnlock()
-	return observed
+	return m.merged().observed
 }
 
 // CorrectWeight returns the weight of correct observations.
 func (m *Accuracy) CorrectWeight() float64 {
-	m.mu.RLock()
-	correct := m.correct
-	m.mu.RUnlock()
-	return correct
+	return m.merged().correct
 }
 
 // Rate returns the rate of correct predictions.
 func (m *Accuracy) Rate() float64 {
-	m.mu.RLock()
-	observed := m.observed
-	correct := m.correct
-	m.mu.RUnlock()
+	return m.merged().rate()
+}
+
+// Snapshot returns a consistent point-in-time snapshot of all values.
+func (m *Accuracy) Snapshot() AccuracySnapshot {
+	snap := m.merged().snapshot()
+	snap.Rejected = m.Rejected()
+	return snap
+}
+
+// SnapshotAndReset atomically returns a snapshot of all values and resets state.
+func (m *Accuracy) SnapshotAndReset() AccuracySnapshot {
+	snap := m.harvest().snapshot()
+	snap.Rejected = m.harvestRejected()
+	return snap
+}
 
-	if observed == 0 {
+// merged returns the merged state of all shards.
+func (m *Accuracy) merged() *accuracyState {
+	st := new(accuracyState)
+	m.shards.each(st.merge)
+	return st
+}
+
+// harvest returns the merged state of all shards and resets them.
+func (m *Accuracy) harvest() *accuracyState {
+	st := new(accuracyState)
+	m.shards.each(func(s *accuracyState) {
+		st.merge(s)
+		*s = accuracyState{}
+	})
+	return st
+}
+
+type accuracyState struct {
+	observed float64
+	correct  float64
+}
+
+func (s *accuracyState) observe(actual, predicted int, weight float64) {
+	s.observed += weight
+	if predicted == actual {
+		s.correct += weight
+	}
+}
+
+func (s *accuracyState) merge(o *accuracyState) {
+	s.observed += o.observed
+	s.correct += o.correct
+}
+
+func (s *accuracyState) rate() float64 {
+	if s.observed == 0 {
 		return 0
 	}
-	return correct / observed
+	return s.correct / s.observed
+}
+
+func (s *accuracyState) snapshot() AccuracySnapshot {
+	return AccuracySnapshot{
+		Weight:  s.observed,
+		Correct: s.correct,
+		Rate:    s.rate(),
+	}
 }
@@ -1,6 +1,11 @@
 package mlmetrics_test
 
 import (
+	"math"
+	"runtime"
+	"sync"
+	"testing"
+
 	. "github.com/bsm/ginkgo"
 	. "github.com/bsm/gomega"
 	"github.com/bsm/mlmetrics"
@@ -36,4 +41,134 @@ var _ = Describe("Accuracy", func() {
 		subject.Observe(-1, -1)
 		Expect(subject.TotalWeight()).To(Equal(12.0))
 	})
+
+	It("should snapshot", func() {
+		snap := subject.Snapshot()
+		subject.Observe(0, 1)
+		Expect(snap).To(Equal(mlmetrics.AccuracySnapshot{Weight: 12, Correct: 9, Rate: 0.75}))
+	})
+
+	It("should snapshot and reset", func() {
+		Expect(subject.SnapshotAndReset()).To(Equal(mlmetrics.AccuracySnapshot{Weight: 12, Correct: 9, Rate: 0.75}))
+		Expect(subject.TotalWeight()).To(BeZero())
+
+		subject.Observe(1, 1)
+		subject.Observe(-1, 1)
+		Expect(subject.SnapshotAndReset()).To(Equal(mlmetrics.AccuracySnapshot{Weight: 1, Correct: 1, Rate: 1, Rejected: mlmetrics.Rejections{InvalidCategory: 1}}))
+		Expect(subject.Rejected()).To(BeZero())
+	})
+
+	It("should not lose observations when harvesting concurrently", func() {
+		subject = mlmetrics.NewAccuracy()
+		total := harvestConcurrently(8000, func(i int) {
+			subject.Observe(i%2, 1)
+		}, func() float64 {
+			return subject.SnapshotAndReset().Weight
+		})
+		Expect(total).To(Equal(8000.0))
+	})
+
+	It("should take consistent snapshots across shards", func() {
+		defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+		subject = mlmetrics.NewAccuracy()
+
+		// writers yield between observations to move across processors and shards
+		var wg sync.WaitGroup
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 2500; i++ {
+					subject.Observe(1, 1)
+					runtime.Gosched()
+					subject.Observe(1, 0)
+				}
+			}()
+		}
+
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+
+		for observing := true; observing; {
+			select {
+			case <-done:
+				observing = false
+			default:
+			}
+
+			// each writer records a correct observation before an incorrect one
+			snap := subject.Snapshot()
+			Expect(2*snap.Correct - snap.Weight).To(And(BeNumerically(">=", 0), BeNumerically("<=", 4)))
+		}
+		Expect(subject.TotalWeight()).To(Equal(20000.0))
+	})
+
+	It("should merge concurrent observations", func() {
+		subject = mlmetrics.NewAccuracy()
+		observeConcurrently(1000, func(i int) {
+			subject.Observe(i%2, i%3%2)
+		})
+		Expect(subject.Snapshot()).To(Equal(mlmetrics.AccuracySnapshot{Weight: 1000, Correct: 501, Rate: 0.501}))
+	})
+
+	It("should observe batches", func() {
+		subject = mlmetrics.NewAccuracy()
+		Expect(subject.ObserveBatch([]int{1, 1, 0, -1}, []int{1, 0, 0, 1}, nil)).To(MatchError(mlmetrics.ErrInvalidCategory))
+		Expect(subject.ObserveBatch([]int{1, 0}, []int{1, 1}, []float64{2, 0})).To(MatchError(mlmetrics.ErrInvalidWeight))
+		Expect(subject.Snapshot()).To(Equal(mlmetrics.AccuracySnapshot{Weight: 5, Correct: 4, Rate: 0.8, Rejected: mlmetrics.Rejections{InvalidCategory: 1, InvalidWeight: 1}}))
+
+		Expect(subject.ObserveBatch([]int{1, 0}, []int{1}, nil)).To(MatchError(mlmetrics.ErrLengthMismatch))
+		Expect(subject.ObserveBatch([]int{1}, []int{1}, []float64{1, 1})).To(MatchError(mlmetrics.ErrLengthMismatch))
+		Expect(subject.TotalWeight()).To(Equal(5.0))
+		Expect(subject.Rejected()).To(Equal(mlmetrics.Rejections{InvalidCategory: 1, InvalidWeight: 1, LengthMismatch: 3}))
+	})
+
+	It("should observe batches of any integer type", func() {
+		subject = mlmetrics.NewAccuracy()
+		Expect(mlmetrics.ObserveAccuracyBatch(subject, []int8{1, 1, 0, -1}, []int64{1, 0, 0, 1}, nil)).To(MatchError(mlmetrics.ErrInvalidCategory))
+		Expect(mlmetrics.ObserveAccuracyBatch(subject, []uint64{1, math.MaxUint64}, []uint{1, 1}, nil)).To(MatchError(mlmetrics.ErrInvalidCategory))
+		Expect(subject.Snapshot()).To(Equal(mlmetrics.AccuracySnapshot{Weight: 4, Correct: 3, Rate: 0.75, Rejected: mlmetrics.Rejections{InvalidCategory: 2}}))
+		Expect(subject.Rejected()).To(Equal(mlmetrics.Rejections{InvalidCategory: 2}))
+
+		Expect(mlmetrics.ObserveAccuracyBatch(subject, []uint16{1, 0}, []uint16{1}, nil)).To(MatchError(mlmetrics.ErrLengthMismatch))
+	})
+
+	It("should count rejected observations", func() {
+		subject.Observe(-1, 1)
+		subject.ObserveWeight(1, 1, 0)
+		Expect(subject.ObserveBatch([]int{1, -1, 1}, []int{1, 1, 1}, []float64{1, 1, -1})).NotTo(Succeed())
+		Expect(subject.TotalWeight()).To(Equal(13.0))
+		Expect(subject.Rejected()).To(Equal(mlmetrics.Rejections{InvalidCategory: 2, InvalidWeight: 2}))
+		Expect(subject.Rejected().Total()).To(Equal(int64(4)))
+
+		subject.Reset()
+		Expect(subject.Rejected()).To(BeZero())
+	})
+
+	It("should report rejected observations", func() {
+		Expect(subject.ObserveWeightStrict(-1, 1, 1)).To(MatchError(mlmetrics.ErrInvalidCategory))
+		Expect(subject.ObserveWeightStrict(1, 1, 0)).To(MatchError(mlmetrics.ErrInvalidWeight))
+		Expect(subject.ObserveWeightStrict(1, 1, 1)).To(Succeed())
+
+		err := subject.ObserveBatch([]int{1, -1, 1}, []int{1, 1, 1}, []float64{1, 1, -1})
+		Expect(err).To(MatchError(mlmetrics.ErrInvalidCategory))
+		Expect(err).To(MatchError("mlmetrics: invalid category (observation 1, 2 rejected)"))
+		Expect(err).To(BeAssignableToTypeOf(&mlmetrics.BatchError{}))
+		Expect(err.(*mlmetrics.BatchError).Index).To(Equal(1))
+		Expect(subject.TotalWeight()).To(Equal(14.0))
+		Expect(subject.Rejected().Total()).To(Equal(int64(4)))
+	})
 })
+
+func BenchmarkAccuracy_ObserveParallel(b *testing.B) {
+	m := mlmetrics.NewAccuracy()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			m.Observe(i%2, i%3%2)
+		}
+	})
+}
@@ -0,0 +1,290 @@
+package mlmetrics
+
+import (
+	"math"
+	"math/rand"
+)
+
+// DistanceFunc calculates the distance between two points.
+type DistanceFunc func(a, b []float64) float64
+
+// EuclideanDistance calculates the Euclidean distance between two points.
+func EuclideanDistance(a, b []float64) float64 {
+	var sum float64
+	for i := range a {
+		d := a[i] - b[i]
+		sum += d * d
+	}
+	return math.Sqrt(sum)
+}
+
+// ManhattanDistance calculates the Manhattan (city block) distance between two points.
+func ManhattanDistance(a, b []float64) float64 {
+	var sum float64
+	for i := range a {
+		sum += math.Abs(a[i] - b[i])
+	}
+	return sum
+}
+
+// CosineDistance calculates the cosine distance (1 - cosine similarity) between two points.
+func CosineDistance(a, b []float64) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if pdt := na * nb; pdt > 0 {
+		return 1 - dot/math.Sqrt(pdt)
+	}
+	return 0
+}
+
+// Silhouette calculates the mean silhouette coefficient of all points, given their
+// cluster assignments. The score is between -1 and 1, higher values indicate dense
+// and well separated clusters. Points with negative cluster IDs are ignored. If dist
+// is nil, EuclideanDistance is used. The calculation requires O(n²) distance
+// computations, consider SilhouetteSample for large inputs.
+// https://en.wikipedia.org/wiki/Silhouette_(clustering)
+func Silhouette(points [][]float64, clusters []int, dist DistanceFunc) (float64, error) {
+	if err := validateClusterInputs(points, clusters); err != nil {
+		return 0, err
+	}
+	if dist == nil {
+		dist = EuclideanDistance
+	}
+
+	points, clusters = filterClusterInputs(points, clusters)
+	return silhouette(points, clusters, dist), nil
+}
+
+// SilhouetteSample approximates the mean silhouette coefficient from a random
+// sample of size points. If rnd is nil, a default source is used.
+func SilhouetteSample(points [][]float64, clusters []int, dist DistanceFunc, size int, rnd *rand.Rand) (float64, error) {
+	if err := validateClusterInputs(points, clusters); err != nil {
+		return 0, err
+	}
+	if dist == nil {
+		dist = EuclideanDistance
+	}
+	if rnd == nil {
+		rnd = rand.New(rand.NewSource(1))
+	}
+
+	points, clusters = filterClusterInputs(points, clusters)
+	if size > 0 && size < len(points) {
+		perm := rnd.Perm(len(points))[:size]
+		sp, sc := make([][]float64, size), make([]int, size)
+		for i, j := range perm {
+			sp[i], sc[i] = points[j], clusters[j]
+		}
+		points, clusters = sp, sc
+	}
+	return silhouette(points, clusters, dist), nil
+}
+
+// DaviesBouldin calculates the Davies-Bouldin index, the mean similarity between each
+// cluster and its most similar one, where similarity is the ratio of within-cluster
+// scatter to between-cluster separation. Lower values indicate better clustering,
+// the minimum score is 0. Cluster centroids are calculated as the mean of their points.
+// Points with negative cluster IDs are ignored. If dist is nil, EuclideanDistance is used.
+// https://en.wikipedia.org/wiki/Davies%E2%80%93Bouldin_index
+func DaviesBouldin(points [][]float64, clusters []int, dist DistanceFunc) (float64, error) {
+	if err := validateClusterInputs(points, clusters); err != nil {
+		return 0, err
+	}
+	if dist == nil {
+		dist = EuclideanDistance
+	}
+
+	points, clusters = filterClusterInputs(points, clusters)
+	centroids, counts := clusterCentroids(points, clusters)
+
+	scatter := make([]float64, len(centroids))
+	for i, p := range points {
+		c := clusters[i]
+		scatter[c] += dist(p, centroids[c]) / counts[c]
+	}
+
+	var sum float64
+	var n int
+	for i := range centroids {
+		if counts[i] == 0 {
+			continue
+		}
+
+		var worst float64
+		for j := range centroids {
+			if i == j || counts[j] == 0 {
+				continue
+			}
+			if d := dist(centroids[i], centroids[j]); d > 0 {
+				if r := (scatter[i] + scatter[j]) / d; r > worst {
+					worst = r
+				}
+			}
+		}
+		sum += worst
+		n++
+	}
+
+	if n < 2 {
+		return 0, nil
+	}
+	return sum / float64(n), nil
+}
+
+// CalinskiHarabasz calculates the Calinski-Harabasz index (aka variance ratio
+// criterion), the ratio of between-cluster to within-cluster dispersion, based on
+// squared Euclidean distances. Higher values indicate better clustering. Points
+// with negative cluster IDs are ignored.
+// https://en.wikipedia.org/wiki/Calinski%E2%80%93Harabasz_index
+func CalinskiHarabasz(points [][]float64, clusters []int) (float64, error) {
+	if err := validateClusterInputs(points, clusters); err != nil {
+		return 0, err
+	}
+
+	points, clusters = filterClusterInputs(points, clusters)
+	centroids, counts := clusterCentroids(points, clusters)
+	if len(points) == 0 {
+		return 0, nil
+	}
+
+	mean := make([]float64, len(points[0]))
+	for _, p := range points {
+		for d, v := range p {
+			mean[d] += v / float64(len(points))
+		}
+	}
+
+	var between, within float64
+	var k int
+	for c, centroid := range centroids {
+		if counts[c] == 0 {
+			continue
+		}
+		d := EuclideanDistance(centroid, mean)
+		between += counts[c] * d * d
+		k++
+	}
+	for i, p := range points {
+		d := EuclideanDistance(p, centroids[clusters[i]])
+		within += d * d
+	}
+
+	n := len(points)
+	if k < 2 || k >= n {
+		return 0, nil
+	}
+	if within == 0 {
+		return 1, nil
+	}
+	return between * float64(n-k) / (within * float64(k-1)), nil
+}
+
+// --------------------------------------------------------------------
+
+func validateClusterInputs(points [][]float64, clusters []int) error {
+	if len(points) != len(clusters) {
+		return ErrLengthMismatch
+	}
+	for _, p := range points {
+		if len(p) != len(points[0]) {
+			return ErrLengthMismatch
+		}
+	}
+	return nil
+}
+
+func filterClusterInputs(points [][]float64, clusters []int) ([][]float64, []int) {
+	for _, c := range clusters {
+		if !isValidCategory(c) {
+			fp := make([][]float64, 0, len(points))
+			fc := make([]int, 0, len(clusters))
+			for i, c := range clusters {
+				if isValidCategory(c) {
+					fp = append(fp, points[i])
+					fc = append(fc, c)
+				}
+			}
+			return fp, fc
+		}
+	}
+	return points, clusters
+}
+
+func clusterCentroids(points [][]float64, clusters []int) ([][]float64, []float64) {
+	var size int
+	for _, c := range clusters {
+		size = maxInt(size, c+1)
+	}
+
+	centroids := make([][]float64, size)
+	counts := make([]float64, size)
+	for i, p := range points {
+		c := clusters[i]
+		if centroids[c] == nil {
+			centroids[c] = make([]float64, len(p))
+		}
+		for d, v := range p {
+			centroids[c][d] += v
+		}
+		counts[c]++
+	}
+	for c, centroid := range centroids {
+		for d := range centroid {
+			centroid[d] /= counts[c]
+		}
+	}
+	return centroids, counts
+}
+
+func silhouette(points [][]float64, clusters []int, dist DistanceFunc) float64 {
+	var size int
+	for _, c := range clusters {
+		size = maxInt(size, c+1)
+	}
+
+	counts := make([]float64, size)
+	var k int
+	for _, c := range clusters {
+		if counts[c] == 0 {
+			k++
+		}
+		counts[c]++
+	}
+	if k < 2 || k >= len(points) {
+		return 0
+	}
+
+	var sum float64
+	sums := make([]float64, size)
+	for i, p := range points {
+		for c := range sums {
+			sums[c] = 0
+		}
+		for j, q := range points {
+			if i != j {
+				sums[clusters[j]] += dist(p, q)
+			}
+		}
+
+		own := clusters[i]
+		if counts[own] < 2 {
+			continue
+		}
+
+		a := sums[own] / (counts[own] - 1)
+		b := math.Inf(1)
+		for c, s := range sums {
+			if c != own && counts[c] > 0 {
+				b = math.Min(b, s/counts[c])
+			}
+		}
+		if div := math.Max(a, b); div > 0 {
+			sum += (b - a) / div
+		}
+	}
+	return sum / float64(len(points))
+}
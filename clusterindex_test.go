@@ -0,0 +1,64 @@
+package mlmetrics_test
+
+import (
+	"math/rand"
+
+	. "github.com/bsm/ginkgo"
+	. "github.com/bsm/gomega"
+	"github.com/bsm/mlmetrics"
+)
+
+var _ = Describe("Internal cluster indices", func() {
+	points := [][]float64{{0}, {1}, {4}, {5}}
+	clusters := []int{0, 0, 1, 1}
+
+	It("should calculate distances", func() {
+		Expect(mlmetrics.EuclideanDistance([]float64{0, 0}, []float64{3, 4})).To(Equal(5.0))
+		Expect(mlmetrics.ManhattanDistance([]float64{0, 0}, []float64{3, -4})).To(Equal(7.0))
+		Expect(mlmetrics.CosineDistance([]float64{1, 0}, []float64{0, 1})).To(Equal(1.0))
+		Expect(mlmetrics.CosineDistance([]float64{1, 1}, []float64{2, 2})).To(BeNumerically("~", 0.0, 1e-9))
+	})
+
+	It("should calculate silhouette", func() {
+		Expect(mlmetrics.Silhouette(points, clusters, nil)).To(BeNumerically("~", 0.746, 0.001))
+		Expect(mlmetrics.Silhouette(points, clusters, mlmetrics.ManhattanDistance)).To(BeNumerically("~", 0.746, 0.001))
+		Expect(mlmetrics.Silhouette(points, []int{0, 1, 0, 1}, nil)).To(BeNumerically("~", -0.375, 0.001))
+		Expect(mlmetrics.Silhouette(points, []int{0, 0, 0, 0}, nil)).To(Equal(0.0))
+		Expect(mlmetrics.Silhouette(points, []int{0, 0, 1, -1}, nil)).To(BeNumerically("~", 0.472, 0.001))
+
+		_, err := mlmetrics.Silhouette(points, []int{0, 1}, nil)
+		Expect(err).To(MatchError(mlmetrics.ErrLengthMismatch))
+	})
+
+	It("should sample silhouette", func() {
+		rnd := rand.New(rand.NewSource(1))
+		var pp [][]float64
+		var cc []int
+		for i := 0; i < 1000; i++ {
+			c := i % 3
+			pp = append(pp, []float64{float64(c*4) + rnd.NormFloat64(), rnd.NormFloat64()})
+			cc = append(cc, c)
+		}
+
+		exact, err := mlmetrics.Silhouette(pp, cc, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mlmetrics.SilhouetteSample(pp, cc, nil, 200, rnd)).To(BeNumerically("~", exact, 0.05))
+		Expect(mlmetrics.SilhouetteSample(pp, cc, nil, 0, nil)).To(Equal(exact))
+	})
+
+	It("should calculate Davies-Bouldin", func() {
+		Expect(mlmetrics.DaviesBouldin(points, clusters, nil)).To(BeNumerically("~", 0.25, 0.001))
+		Expect(mlmetrics.DaviesBouldin(points, []int{0, 1, 0, 1}, nil)).To(BeNumerically("~", 4.0, 0.001))
+		Expect(mlmetrics.DaviesBouldin(points, []int{0, 0, 0, 0}, nil)).To(Equal(0.0))
+
+		_, err := mlmetrics.DaviesBouldin([][]float64{{0}, {1, 2}}, []int{0, 1}, nil)
+		Expect(err).To(MatchError(mlmetrics.ErrLengthMismatch))
+	})
+
+	It("should calculate Calinski-Harabasz", func() {
+		Expect(mlmetrics.CalinskiHarabasz(points, clusters)).To(BeNumerically("~", 32.0, 0.001))
+		Expect(mlmetrics.CalinskiHarabasz(points, []int{0, 1, 0, 1})).To(BeNumerically("~", 0.125, 0.001))
+		Expect(mlmetrics.CalinskiHarabasz(points, []int{0, 0, 0, 0})).To(Equal(0.0))
+		Expect(mlmetrics.CalinskiHarabasz(nil, nil)).To(Equal(0.0))
+	})
+})
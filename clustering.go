@@ -0,0 +1,387 @@
+package mlmetrics
+
+import (
+	"math"
+	"sync"
+)
+
+// Clustering evaluates a clustering against known ground truth classes. Unlike
+// ConfusionMatrix it does not assume that cluster IDs correspond to classes. All
+// scores are invariant to permutations of cluster IDs.
+type Clustering struct {
+	rejector
+	mat resizableMatrix
+	mu  sync.RWMutex
+}
+
+// ClusteringSnapshot is a point-in-time snapshot of a Clustering metric.
+type ClusteringSnapshot struct {
+	Weight               float64    // total weight observed
+	AdjustedRand         float64    // adjusted Rand index
+	MutualInfo           float64    // mutual information
+	NormalizedMutualInfo float64    // normalized mutual information
+	AdjustedMutualInfo   float64    // adjusted mutual information
+	Homogeneity          float64    // homogeneity
+	Completeness         float64    // completeness
+	VMeasure             float64    // V-measure
+	FowlkesMallows       float64    // Fowlkes-Mallows index
+	Rejected             Rejections // observations rejected by validation
+}
+
+// NewClustering inits a new metric.
+func NewClustering() *Clustering {
+	return new(Clustering)
+}
+
+// Reset resets the state.
+func (m *Clustering) Reset() {
+	m.mu.Lock()
+	m.reset()
+	m.mu.Unlock()
+	m.resetRejected()
+}
+
+// Observe records an observation of the actual class vs the assigned cluster.
+func (m *Clustering) Observe(actual, cluster int) {
+	m.ObserveWeight(actual, cluster, 1.0)
+}
+
+// ObserveWeight records an observation of the actual class vs the assigned cluster with a given weight.
+func (m *Clustering) ObserveWeight(actual, cluster int, weight float64) {
+	_ = m.ObserveWeightStrict(actual, cluster, weight)
+}
+
+// ObserveWeightStrict is like ObserveWeight, but returns an error if the observation was
+// rejected by validation, see Rejected.
+func (m *Clustering) ObserveWeightStrict(actual, cluster int, weight float64) error {
+	if err := validateCategories(actual, cluster, weight); err != nil {
+		return m.reject(err)
+	}
+
+	m.mu.Lock()
+	m.observe(actual, cluster, weight)
+	m.mu.Unlock()
+	return nil
+}
+
+// ObserveBatch records a batch of observations of actual classes vs assigned clusters.
+// Weights are optional, if given, they must match the number of observations.
+// Returns ErrLengthMismatch if the lengths of the inputs differ and a *BatchError
+// if observations were rejected.
+func (m *Clustering) ObserveBatch(actual, clusters []int, weights []float64) error {
+	if err := validateBatch(len(actual), weights, len(clusters)); err != nil {
+		return m.rejectBatch(len(actual), err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var berr BatchError
+	for i := range actual {
+		weight := batchWeight(weights, i)
+		if err := validateCategories(actual[i], clusters[i], weight); err != nil {
+			berr.add(i, m.reject(err))
+			continue
+		}
+		m.observe(actual[i], clusters[i], weight)
+	}
+	return berr.errOrNil()
+}
+
+// TotalWeight returns the total weight observed.
+func (m *Clustering) TotalWeight() float64 {
+	m.mu.RLock()
+	sum := m.mat.Sum()
+	m.mu.RUnlock()
+
+	return sum
+}
+
+// AdjustedRand calculates the adjusted Rand index, the similarity between classes and
+// clusters by counting pairs that are assigned together, adjusted for chance.
+// https://en.wikipedia.org/wiki/Rand_index#Adjusted_Rand_index
+func (m *Clustering) AdjustedRand() float64 {
+	return m.readTotals().adjustedRand()
+}
+
+// MutualInfo calculates the mutual information between classes and clusters in nats.
+// https://en.wikipedia.org/wiki/Mutual_information
+func (m *Clustering) MutualInfo() float64 {
+	return m.readTotals().mi
+}
+
+// NormalizedMutualInfo calculates the mutual information normalized by the
+// arithmetic mean of the class and cluster entropies.
+func (m *Clustering) NormalizedMutualInfo() float64 {
+	return m.readTotals().normalizedMutualInfo()
+}
+
+// AdjustedMutualInfo calculates the mutual information adjusted for chance and
+// normalized by the arithmetic mean of the class and cluster entropies. Weights
+// are treated as counts, the calculation may be slow for large totals.
+// https://en.wikipedia.org/wiki/Adjusted_mutual_information
+func (m *Clustering) AdjustedMutualInfo() float64 {
+	return m.readTotals().adjustedMutualInfo()
+}
+
+// Homogeneity calculates the degree to which each cluster contains only members of
+// a single class, between 0 and 1.
+// https://en.wikipedia.org/wiki/Homogeneity_(statistics)
+func (m *Clustering) Homogeneity() float64 {
+	return m.readTotals().homogeneity()
+}
+
+// Completeness calculates the degree to which all members of a class are assigned
+// to the same cluster, between 0 and 1.
+func (m *Clustering) Completeness() float64 {
+	return m.readTotals().completeness()
+}
+
+// VMeasure calculates the harmonic mean of homogeneity and completeness.
+// https://www.aclweb.org/anthology/D07-1043.pdf
+func (m *Clustering) VMeasure() float64 {
+	return m.readTotals().vMeasure()
+}
+
+// FowlkesMallows calculates the Fowlkes-Mallows index, the geometric mean of the
+// pairwise precision and recall.
+// https://en.wikipedia.org/wiki/Fowlkes%E2%80%93Mallows_index
+func (m *Clustering) FowlkesMallows() float64 {
+	return m.readTotals().fowlkesMallows()
+}
+
+// Snapshot returns a consistent point-in-time snapshot of all values.
+func (m *Clustering) Snapshot() ClusteringSnapshot {
+	m.mu.RLock()
+	c := m.clone()
+	m.mu.RUnlock()
+
+	snap := c.snapshot()
+	snap.Rejected = m.Rejected()
+	return snap
+}
+
+// SnapshotAndReset atomically returns a snapshot of all values and resets state.
+func (m *Clustering) SnapshotAndReset() ClusteringSnapshot {
+	m.mu.Lock()
+	c := m.clone()
+	m.reset()
+	m.mu.Unlock()
+
+	snap := c.snapshot()
+	snap.Rejected = m.harvestRejected()
+	return snap
+}
+
+func (m *Clustering) observe(actual, cluster int, weight float64) {
+	m.mat.Set(actual, cluster, m.mat.At(actual, cluster)+weight)
+}
+
+func (m *Clustering) clone() *Clustering {
+	return &Clustering{mat: m.mat.Copy()}
+}
+
+func (m *Clustering) reset() {
+	m.mat = resizableMatrix{}
+}
+
+func (m *Clustering) snapshot() ClusteringSnapshot {
+	t := m.totals()
+	return ClusteringSnapshot{
+		Weight:               t.sum,
+		AdjustedRand:         t.adjustedRand(),
+		MutualInfo:           t.mi,
+		NormalizedMutualInfo: t.normalizedMutualInfo(),
+		AdjustedMutualInfo:   t.adjustedMutualInfo(),
+		Homogeneity:          t.homogeneity(),
+		Completeness:         t.completeness(),
+		VMeasure:             t.vMeasure(),
+		FowlkesMallows:       t.fowlkesMallows(),
+	}
+}
+
+// readTotals calculates the totals under a read lock.
+func (m *Clustering) readTotals() clusteringTotals {
+	m.mu.RLock()
+	t := m.totals()
+	m.mu.RUnlock()
+
+	return t
+}
+
+// totals calculates the marginal sums, the entropies and the mutual information
+// in two passes over the matrix.
+func (m *Clustering) totals() clusteringTotals {
+	size := m.mat.size
+	t := clusteringTotals{
+		rows: make([]float64, size),
+		cols: make([]float64, size),
+	}
+	for i := 0; i < size; i++ {
+		for j := 0; j < size; j++ {
+			v := m.mat.At(i, j)
+			t.rows[i] += v
+			t.cols[j] += v
+			t.sum += v
+			t.pairs += pairCount(v)
+			t.squares += v * v
+		}
+	}
+	if t.sum == 0 {
+		return t
+	}
+
+	for i := 0; i < size; i++ {
+		if p := t.rows[i] / t.sum; p > 0 {
+			t.hc -= p * math.Log(p)
+		}
+		if p := t.cols[i] / t.sum; p > 0 {
+			t.hk -= p * math.Log(p)
+		}
+		for j := 0; j < size; j++ {
+			if v := m.mat.At(i, j); v > 0 {
+				t.mi += v / t.sum * math.Log(v*t.sum/(t.rows[i]*t.cols[j]))
+			}
+		}
+	}
+	return t
+}
+
+// clusteringTotals contains the marginal sums and entropies of a clustering.
+type clusteringTotals struct {
+	sum     float64   // total weight
+	pairs   float64   // number of pairs within each cell
+	squares float64   // sum of squared cell weights
+	rows    []float64 // weights of each class
+	cols    []float64 // weights of each cluster
+	hc, hk  float64   // class and cluster entropies
+	mi      float64   // mutual information
+}
+
+func (t clusteringTotals) adjustedRand() float64 {
+	if t.sum == 0 {
+		return 0.0
+	}
+
+	var rows, cols float64
+	for i := range t.rows {
+		rows += pairCount(t.rows[i])
+		cols += pairCount(t.cols[i])
+	}
+
+	total := pairCount(t.sum)
+	if total <= 0 {
+		return 1.0
+	}
+
+	expected := rows * cols / total
+	maximum := (rows + cols) / 2
+	if div := maximum - expected; div != 0 {
+		return (t.pairs - expected) / div
+	}
+	return 1.0
+}
+
+func (t clusteringTotals) normalizedMutualInfo() float64 {
+	if t.sum == 0 {
+		return 0.0
+	}
+	if t.hc == 0 && t.hk == 0 {
+		return 1.0
+	}
+	return t.mi / ((t.hc + t.hk) / 2)
+}
+
+func (t clusteringTotals) adjustedMutualInfo() float64 {
+	if t.sum == 0 {
+		return 0.0
+	}
+	if t.hc == 0 && t.hk == 0 {
+		return 1.0
+	}
+
+	emi := t.expectedMutualInfo()
+	if div := (t.hc+t.hk)/2 - emi; div != 0 {
+		return (t.mi - emi) / div
+	}
+	return 0.0
+}
+
+func (t clusteringTotals) homogeneity() float64 {
+	if t.sum == 0 {
+		return 0.0
+	}
+	if t.hc == 0 {
+		return 1.0
+	}
+	return t.mi / t.hc
+}
+
+func (t clusteringTotals) completeness() float64 {
+	if t.sum == 0 {
+		return 0.0
+	}
+	if t.hk == 0 {
+		return 1.0
+	}
+	return t.mi / t.hk
+}
+
+func (t clusteringTotals) vMeasure() float64 {
+	homogeneity := t.homogeneity()
+	completeness := t.completeness()
+
+	if sum := homogeneity + completeness; sum > 0 {
+		return 2 * homogeneity * completeness / sum
+	}
+	return 0.0
+}
+
+func (t clusteringTotals) fowlkesMallows() float64 {
+	var pk, qk float64
+	for i := range t.rows {
+		pk += t.cols[i] * t.cols[i]
+		qk += t.rows[i] * t.rows[i]
+	}
+	tk := t.squares - t.sum
+	pk -= t.sum
+	qk -= t.sum
+
+	if tk > 0 && pk > 0 && qk > 0 {
+		return tk / math.Sqrt(pk*qk)
+	}
+	return 0.0
+}
+
+// expectedMutualInfo calculates the expected mutual information under the
+// hypergeometric model of randomness.
+func (t clusteringTotals) expectedMutualInfo() (emi float64) {
+	n := math.Round(t.sum)
+	lgN1 := lgamma(n + 1)
+
+	for _, a := range t.rows {
+		if a = math.Round(a); a == 0 {
+			continue
+		}
+		for _, b := range t.cols {
+			if b = math.Round(b); b == 0 {
+				continue
+			}
+
+			gln := lgamma(a+1) + lgamma(b+1) + lgamma(n-a+1) + lgamma(n-b+1) - lgN1
+			for nij := math.Max(1, a+b-n); nij <= math.Min(a, b); nij++ {
+				term1 := nij / n * math.Log(n*nij/(a*b))
+				term2 := gln - lgamma(nij+1) - lgamma(a-nij+1) - lgamma(b-nij+1) - lgamma(n-a-b+nij+1)
+				emi += term1 * math.Exp(term2)
+			}
+		}
+	}
+	return
+}
+
+func pairCount(n float64) float64 { return n * (n - 1) / 2 }
+
+func lgamma(x float64) float64 {
+	v, _ := math.Lgamma(x)
+	return v
+}
@@ -0,0 +1,106 @@
+package mlmetrics_test
+
+import (
+	. "github.com/bsm/ginkgo"
+	. "github.com/bsm/gomega"
+	"github.com/bsm/mlmetrics"
+)
+
+var _ = Describe("Clustering", func() {
+	var subject *mlmetrics.Clustering
+
+	observe := func(actual, clusters []int) {
+		for i := range actual {
+			subject.Observe(actual[i], clusters[i])
+		}
+	}
+
+	BeforeEach(func() {
+		subject = mlmetrics.NewClustering()
+	})
+
+	It("should calculate scores", func() {
+		observe([]int{0, 0, 0, 1, 1, 1, 2, 2, 2}, []int{0, 0, 1, 1, 1, 2, 2, 2, 2})
+		Expect(subject.TotalWeight()).To(Equal(9.0))
+		Expect(subject.AdjustedRand()).To(BeNumerically("~", 0.357, 0.001))
+		Expect(subject.MutualInfo()).To(BeNumerically("~", 0.637, 0.001))
+		Expect(subject.NormalizedMutualInfo()).To(BeNumerically("~", 0.590, 0.001))
+		Expect(subject.AdjustedMutualInfo()).To(BeNumerically("~", 0.409, 0.001))
+		Expect(subject.Homogeneity()).To(BeNumerically("~", 0.579, 0.001))
+		Expect(subject.Completeness()).To(BeNumerically("~", 0.600, 0.001))
+		Expect(subject.VMeasure()).To(BeNumerically("~", 0.590, 0.001))
+		Expect(subject.FowlkesMallows()).To(BeNumerically("~", 0.527, 0.001))
+	})
+
+	It("should calculate scores (split clusters)", func() {
+		observe([]int{0, 0, 1, 1}, []int{0, 0, 1, 2})
+		Expect(subject.AdjustedRand()).To(BeNumerically("~", 0.571, 0.001))
+		Expect(subject.AdjustedMutualInfo()).To(BeNumerically("~", 0.571, 0.001))
+		Expect(subject.Homogeneity()).To(BeNumerically("~", 1.0, 0.001))
+		Expect(subject.Completeness()).To(BeNumerically("~", 0.667, 0.001))
+		Expect(subject.VMeasure()).To(BeNumerically("~", 0.8, 0.001))
+		Expect(subject.FowlkesMallows()).To(BeNumerically("~", 0.707, 0.001))
+	})
+
+	It("should ignore cluster IDs", func() {
+		observe([]int{0, 0, 1, 1}, []int{1, 1, 0, 0})
+		Expect(subject.AdjustedRand()).To(BeNumerically("~", 1.0, 0.001))
+		Expect(subject.NormalizedMutualInfo()).To(BeNumerically("~", 1.0, 0.001))
+		Expect(subject.AdjustedMutualInfo()).To(BeNumerically("~", 1.0, 0.001))
+		Expect(subject.VMeasure()).To(BeNumerically("~", 1.0, 0.001))
+		Expect(subject.FowlkesMallows()).To(BeNumerically("~", 1.0, 0.001))
+	})
+
+	It("should calculate independent assignments", func() {
+		observe([]int{0, 0, 0, 0}, []int{0, 1, 2, 3})
+		Expect(subject.AdjustedRand()).To(BeNumerically("~", 0.0, 0.001))
+		Expect(subject.AdjustedMutualInfo()).To(BeNumerically("~", 0.0, 0.001))
+		Expect(subject.Homogeneity()).To(BeNumerically("~", 1.0, 0.001))
+		Expect(subject.Completeness()).To(BeNumerically("~", 0.0, 0.001))
+		Expect(subject.FowlkesMallows()).To(Equal(0.0))
+	})
+
+	It("should calculate weighted scores", func() {
+		subject.ObserveWeight(0, 0, 2)
+		subject.ObserveWeight(1, 1, 1)
+		subject.ObserveWeight(1, 2, 1)
+		Expect(subject.TotalWeight()).To(Equal(4.0))
+		Expect(subject.AdjustedRand()).To(BeNumerically("~", 0.571, 0.001))
+		Expect(subject.VMeasure()).To(BeNumerically("~", 0.8, 0.001))
+	})
+
+	It("should handle blanks", func() {
+		Expect(subject.TotalWeight()).To(Equal(0.0))
+		Expect(subject.AdjustedRand()).To(Equal(0.0))
+		Expect(subject.MutualInfo()).To(Equal(0.0))
+		Expect(subject.NormalizedMutualInfo()).To(Equal(0.0))
+		Expect(subject.AdjustedMutualInfo()).To(Equal(0.0))
+		Expect(subject.VMeasure()).To(Equal(0.0))
+		Expect(subject.FowlkesMallows()).To(Equal(0.0))
+
+		subject.Observe(1, 1)
+		subject.Reset()
+		Expect(subject.TotalWeight()).To(Equal(0.0))
+	})
+
+	It("should not lose observations when harvesting concurrently", func() {
+		total := harvestConcurrently(4000, func(i int) {
+			subject.Observe(i%3, i%2)
+		}, func() float64 {
+			return subject.SnapshotAndReset().Weight
+		})
+		Expect(total).To(Equal(4000.0))
+	})
+
+	It("should observe batches", func() {
+		expected := mlmetrics.NewClustering()
+		expected.Observe(0, 1)
+		expected.Observe(0, 1)
+		expected.ObserveWeight(1, 0, 2)
+
+		subject = mlmetrics.NewClustering()
+		Expect(subject.ObserveBatch([]int{0, 0, 1, -1}, []int{1, 1, 0, 0}, []float64{1, 1, 2, 1})).To(MatchError(mlmetrics.ErrInvalidCategory))
+		Expect(subject.Snapshot()).To(equalSnapshot(expected.Snapshot()))
+		Expect(subject.ObserveBatch([]int{0}, []int{}, nil)).To(MatchError(mlmetrics.ErrLengthMismatch))
+	})
+})
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/bsm/mlmetrics"
+)
+
+// metric is a named metric value.
+type metric struct {
+	Name  string
+	Value float64
+}
+
+// evaluator evaluates records.
+type evaluator interface {
+	Observe(*record) error
+	Metrics() []metric
+}
+
+// --------------------------------------------------------------------
+
+type classification struct {
+	confusion *mlmetrics.ConfusionMatrix
+	logLoss   *mlmetrics.LogLoss
+	gains     *mlmetrics.Gains
+	scored    bool
+}
+
+func newClassification() evaluator {
+	return &classification{
+		confusion: mlmetrics.NewConfusionMatrix(),
+		logLoss:   mlmetrics.NewLogLoss(),
+		gains:     mlmetrics.NewGains(),
+	}
+}
+
+func (e *classification) Observe(rec *record) error {
+	actual, ok := category(rec.Actual)
+	if !ok {
+		return fmt.Errorf("invalid actual category %v", rec.Actual)
+	}
+	predicted, ok := category(rec.Predicted)
+	if !ok {
+		return fmt.Errorf("invalid predicted category %v", rec.Predicted)
+	}
+
+	e.confusion.ObserveWeight(actual, predicted, rec.Weight)
+	if rec.HasScore {
+		e.scored = true
+
+		prob := rec.Score
+		if actual != 1 {
+			prob = 1 - prob
+		}
+		e.logLoss.ObserveWeight(prob, rec.Weight)
+		e.gains.ObserveWeight(rec.Score, actual == 1, rec.Weight)
+	}
+	return nil
+}
+
+func (e *classification) Metrics() []metric {
+	snap := e.confusion.Snapshot()
+	metrics := []metric{
+		{"weight", snap.Weight},
+		{"accuracy", snap.Accuracy},
+		{"kappa", snap.Kappa},
+		{"matthews", snap.Matthews},
+	}
+	for x, c := range snap.Classes {
+		suffix := "[" + strconv.Itoa(x) + "]"
+		metrics = append(metrics,
+			metric{"precision" + suffix, c.Precision},
+			metric{"sensitivity" + suffix, c.Sensitivity},
+			metric{"f1" + suffix, c.F1},
+		)
+	}
+	if e.scored {
+		metrics = append(metrics,
+			metric{"logloss", e.logLoss.Score()},
+			metric{"auc", e.gains.AUC()},
+			metric{"gini", e.gains.Gini()},
+			metric{"ks", e.gains.KS()},
+		)
+	}
+	return metrics
+}
+
+func category(v float64) (int, bool) {
+	if v < 0 || v != math.Trunc(v) || v > math.MaxInt32 {
+		return 0, false
+	}
+	return int(v), true
+}
+
+// --------------------------------------------------------------------
+
+type regression struct {
+	regression *mlmetrics.Regression
+}
+
+func newRegression() evaluator {
+	return &regression{regression: mlmetrics.NewRegression()}
+}
+
+func (e *regression) Observe(rec *record) error {
+	e.regression.ObserveWeight(rec.Actual, rec.Predicted, rec.Weight)
+	return nil
+}
+
+func (e *regression) Metrics() []metric {
+	snap := e.regression.Snapshot()
+	return []metric{
+		{"weight", snap.Weight},
+		{"mae", snap.MAE},
+		{"mse", snap.MSE},
+		{"rmse", snap.RMSE},
+		{"msle", snap.MSLE},
+		{"rmsle", snap.RMSLE},
+		{"r2", snap.R2},
+		{"max_error", snap.MaxError},
+	}
+}
+
+// --------------------------------------------------------------------
+
+// ranking collects results of each query, identified by group, and evaluates
+// them in the order of their predicted scores.
+type ranking struct {
+	k       int
+	queries map[string]*rankingQuery
+	order   []string
+}
+
+type rankingQuery struct {
+	weight  float64
+	results []rankingResult
+}
+
+type rankingResult struct {
+	relevance float64
+	score     float64
+}
+
+func newRanking(k int) *ranking {
+	return &ranking{k: k, queries: make(map[string]*rankingQuery)}
+}
+
+func (e *ranking) Observe(rec *record) error {
+	q, ok := e.queries[rec.Group]
+	if !ok {
+		q = &rankingQuery{weight: rec.Weight}
+		e.queries[rec.Group] = q
+		e.order = append(e.order, rec.Group)
+	}
+	q.results = append(q.results, rankingResult{relevance: rec.Actual, score: rec.Predicted})
+	return nil
+}
+
+func (e *ranking) Metrics() []metric {
+	m := mlmetrics.NewRanking(e.k)
+	for _, group := range e.order {
+		q := e.queries[group]
+		sort.SliceStable(q.results, func(i, j int) bool { return q.results[i].score > q.results[j].score })
+
+		relevance := make([]float64, len(q.results))
+		for i, r := range q.results {
+			relevance[i] = r.relevance
+		}
+		m.ObserveWeight(relevance, q.weight)
+	}
+
+	return []metric{
+		{"weight", m.TotalWeight()},
+		{"ndcg", m.NDCG()},
+		{"map", m.MAP()},
+		{"mrr", m.MRR()},
+		{"precision", m.Precision()},
+		{"recall", m.Recall()},
+		{"hit_rate", m.HitRate()},
+	}
+}
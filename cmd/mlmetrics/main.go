@@ -0,0 +1,140 @@
+// Command mlmetrics evaluates predictions stored in CSV or JSONL files.
+//
+// Usage:
+//
+//	mlmetrics [flags] [file ...]
+//
+// Records are read from the given files, or from stdin if no files (or "-") are
+// given. CSV inputs must start with a header row, JSONL inputs must contain one
+// object per line. The input format is derived from the file extension, unless
+// specified explicitly.
+//
+// Examples:
+//
+//	mlmetrics -task classification -score prob predictions.csv
+//	mlmetrics -task regression -group country -output markdown predictions.jsonl
+//	cat results.csv | mlmetrics -task ranking -group query -k 10
+//
+// For classification, actual and predicted values are categories (non-negative
+// integers, or true/false). An optional score column, the predicted probability
+// of category 1, enables log-loss, AUC, Gini and KS. For ranking, the group column
+// identifies queries, actual values are relevance grades and predicted values are
+// scores used to rank the results of each query, the weight of a query is taken from
+// its first row. For classification and regression, the optional group column
+// produces a breakdown of metrics per group.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bsm/mlmetrics"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "mlmetrics:", err)
+		}
+		os.Exit(2)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	var cols columns
+
+	flags := flag.NewFlagSet("mlmetrics", flag.ContinueOnError)
+	task := flags.String("task", "classification", "task type: classification, regression or ranking")
+	input := flags.String("input", "", "input format: csv or jsonl (default: derived from file extension, csv for stdin)")
+	output := flags.String("output", "text", "output format: text, json or markdown")
+	k := flags.Int("k", 0, "cut-off rank for ranking metrics, 0 evaluates complete result lists")
+	flags.StringVar(&cols.Actual, "actual", "actual", "name of the actual value column")
+	flags.StringVar(&cols.Predicted, "predicted", "predicted", "name of the predicted value column")
+	flags.StringVar(&cols.Weight, "weight", "", "name of the (optional) weight column")
+	flags.StringVar(&cols.Score, "score", "", "name of the (optional) score column")
+	flags.StringVar(&cols.Group, "group", "", "name of the (optional) group column")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var factory func() evaluator
+	switch *task {
+	case "classification":
+		factory = newClassification
+	case "regression":
+		factory = newRegression
+	case "ranking":
+		if cols.Group == "" {
+			return errors.New("ranking requires a group column")
+		}
+		factory = func() evaluator { return newRanking(*k) }
+	default:
+		return fmt.Errorf("unsupported task %q", *task)
+	}
+
+	overall := factory()
+	var groups *mlmetrics.Segmented[evaluator]
+	if cols.Group != "" && *task != "ranking" {
+		groups = mlmetrics.NewSegmented(factory)
+	}
+
+	observe := func(rec *record) error {
+		if err := overall.Observe(rec); err != nil {
+			return err
+		}
+		if groups != nil {
+			return groups.In(rec.Group).Observe(rec)
+		}
+		return nil
+	}
+
+	files := flags.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	for _, name := range files {
+		if err := readFile(name, *input, stdin, &cols, observe); err != nil {
+			return err
+		}
+	}
+
+	rep := &report{Task: *task, Metrics: overall.Metrics()}
+	if groups != nil {
+		for _, key := range groups.Keys() {
+			ev, _ := groups.Get(key)
+			rep.Groups = append(rep.Groups, groupReport{Name: key, Metrics: ev.Metrics()})
+		}
+	}
+	return rep.Write(stdout, *output)
+}
+
+func readFile(name, format string, stdin io.Reader, cols *columns, fn func(*record) error) error {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".jsonl", ".ndjson", ".json":
+			format = "jsonl"
+		default:
+			format = "csv"
+		}
+	}
+
+	if name == "-" {
+		return readRecords(stdin, format, cols, fn)
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := readRecords(f, format, cols, fn); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
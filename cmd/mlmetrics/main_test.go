@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	. "github.com/bsm/ginkgo"
+	. "github.com/bsm/gomega"
+)
+
+var _ = Describe("run", func() {
+	const classificationCSV = "actual,predicted,prob,country\n" +
+		"1,1,0.9,de\n" +
+		"1,0,0.4,de\n" +
+		"0,0,0.2,fr\n" +
+		"0,1,0.6,fr\n" +
+		"1,1,0.8,fr\n"
+
+	exec := func(input string, args ...string) (string, error) {
+		var out bytes.Buffer
+		err := run(args, strings.NewReader(input), &out)
+		return out.String(), err
+	}
+
+	It("should evaluate classifications", func() {
+		out, err := exec(classificationCSV, "-score", "prob", "-output", "json")
+		Expect(err).NotTo(HaveOccurred())
+
+		var res struct {
+			Task    string
+			Metrics map[string]float64
+		}
+		Expect(json.Unmarshal([]byte(out), &res)).To(Succeed())
+		Expect(res.Task).To(Equal("classification"))
+		Expect(res.Metrics).To(HaveKeyWithValue("weight", 5.0))
+		Expect(res.Metrics).To(HaveKeyWithValue("accuracy", 0.6))
+		Expect(res.Metrics).To(HaveKeyWithValue("auc", BeNumerically("~", 0.833, 0.001)))
+		Expect(res.Metrics).To(HaveKeyWithValue("logloss", BeNumerically("~", 0.477, 0.001)))
+	})
+
+	It("should break down by group", func() {
+		out, err := exec(classificationCSV, "-group", "country")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(HavePrefix("metric          overall   de        fr\n"))
+		Expect(out).To(ContainSubstring("accuracy        0.600000  0.500000  0.666667\n"))
+		Expect(out).NotTo(ContainSubstring("auc"))
+	})
+
+	It("should evaluate regressions", func() {
+		input := `{"actual":1.5,"predicted":2,"w":1}` + "\n" + `{"actual":3,"predicted":2.5,"w":"3"}` + "\n"
+		out, err := exec(input, "-task", "regression", "-input", "jsonl", "-weight", "w", "-output", "markdown")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(HavePrefix("| metric | overall |\n| :-- | --- |\n| weight | 4 |\n| mae | 0.500000 |\n"))
+	})
+
+	It("should evaluate rankings", func() {
+		input := "q,rel,score\na,1,0.3\na,0,0.9\nb,2,0.5\n"
+		out, err := exec(input, "-task", "ranking", "-group", "q", "-actual", "rel", "-predicted", "score")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(ContainSubstring("mrr        0.750000\n"))
+		Expect(out).To(ContainSubstring("recall     1\n"))
+	})
+
+	It("should fail on invalid inputs", func() {
+		_, err := exec("actual,predicted\n1,x\n")
+		Expect(err).To(MatchError(`line 2: invalid value for "predicted": "x"`))
+
+		_, err = exec("actual,predicted\n1.5,1\n")
+		Expect(err).To(MatchError(`invalid actual category 1.5`))
+
+		_, err = exec("actual\n1\n")
+		Expect(err).To(MatchError(`column "predicted" not found`))
+
+		_, err = exec("", "-task", "ranking")
+		Expect(err).To(MatchError(`ranking requires a group column`))
+
+		_, err = exec("", "-output", "xml")
+		Expect(err).To(MatchError(`unsupported output format "xml"`))
+	})
+})
+
+func TestSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "mlmetrics/cmd/mlmetrics")
+}
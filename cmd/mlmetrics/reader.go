@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// record is a single input row.
+type record struct {
+	Actual    float64
+	Predicted float64
+	Weight    float64
+	Score     float64
+	HasScore  bool
+	Group     string
+}
+
+// columns maps record fields to input column names.
+type columns struct {
+	Actual, Predicted, Weight, Score, Group string
+}
+
+// readRecords reads records from r in the given format (csv or jsonl) and passes
+// them to fn.
+func readRecords(r io.Reader, format string, cols *columns, fn func(*record) error) error {
+	switch format {
+	case "csv":
+		return readCSV(r, cols, fn)
+	case "jsonl":
+		return readJSONL(r, cols, fn)
+	}
+	return fmt.Errorf("unsupported input format %q", format)
+}
+
+func readCSV(r io.Reader, cols *columns, fn func(*record) error) error {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.TrimSpace(name)] = i
+	}
+
+	for _, name := range []string{cols.Actual, cols.Predicted, cols.Weight, cols.Score, cols.Group} {
+		if _, ok := index[name]; name != "" && !ok {
+			return fmt.Errorf("column %q not found", name)
+		}
+	}
+
+	for line := 2; ; line++ {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		rec, err := parseRecord(cols, func(name string) (string, bool) {
+			if i, ok := index[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i]), true
+			}
+			return "", false
+		})
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+}
+
+func readJSONL(r io.Reader, cols *columns, fn func(*record) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		data := scanner.Bytes()
+		if len(strings.TrimSpace(string(data))) == 0 {
+			continue
+		}
+
+		var obj map[string]interface{}
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+
+		rec, err := parseRecord(cols, func(name string) (string, bool) {
+			switch v := obj[name].(type) {
+			case string:
+				return v, true
+			case float64:
+				return strconv.FormatFloat(v, 'f', -1, 64), true
+			case bool:
+				if v {
+					return "1", true
+				}
+				return "0", true
+			}
+			return "", false
+		})
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func parseRecord(cols *columns, get func(string) (string, bool)) (*record, error) {
+	rec := &record{Weight: 1}
+
+	var err error
+	if rec.Actual, err = parseValue(cols.Actual, get); err != nil {
+		return nil, err
+	}
+	if rec.Predicted, err = parseValue(cols.Predicted, get); err != nil {
+		return nil, err
+	}
+	if cols.Weight != "" {
+		if rec.Weight, err = parseValue(cols.Weight, get); err != nil {
+			return nil, err
+		}
+	}
+	if cols.Score != "" {
+		if rec.Score, err = parseValue(cols.Score, get); err != nil {
+			return nil, err
+		}
+		rec.HasScore = true
+	}
+	if cols.Group != "" {
+		rec.Group, _ = get(cols.Group)
+	}
+	return rec, nil
+}
+
+func parseValue(name string, get func(string) (string, bool)) (float64, error) {
+	s, ok := get(name)
+	if !ok {
+		return 0, fmt.Errorf("missing value for %q", name)
+	}
+
+	switch strings.ToLower(s) {
+	case "true":
+		return 1, nil
+	case "false":
+		return 0, nil
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %q: %q", name, s)
+	}
+	return v, nil
+}
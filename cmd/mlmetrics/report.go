@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// report contains the overall and (optional) per-group metrics.
+type report struct {
+	Task    string
+	Metrics []metric
+	Groups  []groupReport
+}
+
+type groupReport struct {
+	Name    string
+	Metrics []metric
+}
+
+func (r *report) Write(w io.Writer, format string) error {
+	switch format {
+	case "text":
+		return r.writeText(w)
+	case "json":
+		return r.writeJSON(w)
+	case "markdown":
+		return r.writeMarkdown(w)
+	}
+	return fmt.Errorf("unsupported output format %q", format)
+}
+
+// table returns the report as rows of metrics with a column for the overall value
+// and each group.
+func (r *report) table() [][]string {
+	header := []string{"metric", "overall"}
+	for _, g := range r.Groups {
+		header = append(header, g.Name)
+	}
+
+	rows := [][]string{header}
+	for _, m := range r.Metrics {
+		row := []string{m.Name, formatValue(m.Value)}
+		for _, g := range r.Groups {
+			cell := "-"
+			for _, gm := range g.Metrics {
+				if gm.Name == m.Name {
+					cell = formatValue(gm.Value)
+					break
+				}
+			}
+			row = append(row, cell)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func (r *report) writeText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for _, row := range r.table() {
+		if _, err := fmt.Fprintln(tw, strings.Join(row, "\t")); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+func (r *report) writeMarkdown(w io.Writer) error {
+	rows := r.table()
+	for i, row := range rows {
+		if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(row, " | ")); err != nil {
+			return err
+		}
+		if i == 0 {
+			sep := make([]string, len(row))
+			for j := range sep {
+				sep[j] = "---"
+			}
+			sep[0] = ":--"
+			if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(sep, " | ")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *report) writeJSON(w io.Writer) error {
+	type jsonReport struct {
+		Task    string                        `json:"task"`
+		Metrics map[string]float64            `json:"metrics"`
+		Groups  map[string]map[string]float64 `json:"groups,omitempty"`
+	}
+
+	res := jsonReport{Task: r.Task, Metrics: jsonMetrics(r.Metrics)}
+	if len(r.Groups) != 0 {
+		res.Groups = make(map[string]map[string]float64, len(r.Groups))
+		for _, g := range r.Groups {
+			res.Groups[g.Name] = jsonMetrics(g.Metrics)
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(res)
+}
+
+// jsonMetrics converts metrics to a map, omitting non-finite values which cannot
+// be encoded as JSON.
+func jsonMetrics(metrics []metric) map[string]float64 {
+	res := make(map[string]float64, len(metrics))
+	for _, m := range metrics {
+		if !math.IsNaN(m.Value) && !math.IsInf(m.Value, 0) {
+			res[m.Name] = m.Value
+		}
+	}
+	return res
+}
+
+func formatValue(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(v, 'f', 6, 64)
+}
@@ -2,14 +2,34 @@ package mlmetrics
 
 import (
 	"math"
-	"sync"
 )
 
 // ConfusionMatrix can be used to visualize the performance of a binary
 // classifier.
 type ConfusionMatrix struct {
-	mat resizableMatrix
-	mu  sync.RWMutex
+	rejector
+	costs  CostMatrix
+	shards shards[confusionState]
+}
+
+// ConfusionMatrixSnapshot is a point-in-time snapshot of a ConfusionMatrix.
+type ConfusionMatrixSnapshot struct {
+	Weight      float64         // total weight observed
+	Accuracy    float64         // overall accuracy rate
+	Kappa       float64         // Cohen's kappa
+	Matthews    float64         // Matthews correlation coefficient
+	Matrix      [][]float64     // rows of actual, columns of predicted categories
+	Classes     []ClassSnapshot // scores of each category
+	TotalCost   float64         // total cost, as defined by the attached cost matrix
+	AverageCost float64         // average cost, as defined by the attached cost matrix
+	Rejected    Rejections      // observations rejected by validation
+}
+
+// ClassSnapshot contains the scores of a single category.
+type ClassSnapshot struct {
+	Precision   float64 // positive predictive value
+	Sensitivity float64 // recall
+	F1          float64 // F1 score
 }
 
 // NewConfusionMatrix inits a new ConfusionMatrix.
@@ -17,11 +37,16 @@ func NewConfusionMatrix() *ConfusionMatrix {
 	return new(ConfusionMatrix)
 }
 
+// NewConfusionMatrixWithCosts inits a new ConfusionMatrix with an attached
+// cost matrix, which enables cost-sensitive evaluation. The matrix is copied.
+func NewConfusionMatrixWithCosts(costs CostMatrix) *ConfusionMatrix {
+	return &ConfusionMatrix{costs: costs.clone()}
+}
+
 // Reset resets the state.
 func (m *ConfusionMatrix) Reset() {
-	m.mu.Lock()
-	m.mat = resizableMatrix{}
-	m.mu.Unlock()
+	m.shards.each(func(s *confusionState) { *s = confusionState{} })
+	m.resetRejected()
 }
 
 // Observe records an observation of the actual vs the predicted category.
@@ -31,123 +56,99 @@ func (m *ConfusionMatrix) Observe(actual, predicted int) {
 
 // ObserveWeight records an observation of the actual vs the predicted category with a given weight.
 func (m *ConfusionMatrix) ObserveWeight(actual, predicted int, weight float64) {
-	if !isValidCategory(actual) || !isValidCategory(predicted) || !isValidWeight(weight) {
-		return
+	_ = m.ObserveWeightStrict(actual, predicted, weight)
+}
+
+// ObserveWeightStrict is like ObserveWeight, but returns an error if the observation was
+// rejected by validation, see Rejected.
+func (m *ConfusionMatrix) ObserveWeightStrict(actual, predicted int, weight float64) error {
+	if err := validateCategories(actual, predicted, weight); err != nil {
+		return m.reject(err)
 	}
 
-	m.mu.Lock()
-	m.mat.Set(actual, predicted, m.mat.At(actual, predicted)+weight)
-	m.mu.Unlock()
+	sh := m.shards.acquire()
+	sh.state.observe(actual, predicted, weight)
+	m.shards.release(sh)
+	return nil
 }
 
-// Order returns the matrix order (number or rows/cols).
-func (m *ConfusionMatrix) Order() int {
-	m.mu.RLock()
-	size := m.mat.size
-	m.mu.RUnlock()
+// ObserveBatch records a batch of observations of actual vs predicted categories.
+// Weights are optional, if given, they must match the number of observations.
+// Returns ErrLengthMismatch if the lengths of the inputs differ and a *BatchError
+// if observations were rejected.
+func (m *ConfusionMatrix) ObserveBatch(actual, predicted []int, weights []float64) error {
+	return ObserveConfusionMatrixBatch(m, actual, predicted, weights)
+}
 
-	return size
+// ObserveConfusionMatrixBatch records a batch of observations of actual vs predicted categories
+// of any integer type without converting the inputs first. Categories which cannot be
+// represented as a non-negative int are rejected. See ConfusionMatrix.ObserveBatch.
+func ObserveConfusionMatrixBatch[A, P Integer](m *ConfusionMatrix, actual []A, predicted []P, weights []float64) error {
+	if err := validateBatch(len(actual), weights, len(predicted)); err != nil {
+		return m.rejectBatch(len(actual), err)
+	}
+
+	var berr BatchError
+	sh := m.shards.acquire()
+	for i := range actual {
+		act, pred, weight := categoryOf(actual[i]), categoryOf(predicted[i]), batchWeight(weights, i)
+		if err := validateCategories(act, pred, weight); err != nil {
+			berr.add(i, m.reject(err))
+			continue
+		}
+		sh.state.observe(act, pred, weight)
+	}
+	m.shards.release(sh)
+	return berr.errOrNil()
 }
 
-// TotalWeight returns the total weight observed (sum of the matrix).
-func (m *ConfusionMatrix) TotalWeight() float64 {
-	m.mu.RLock()
-	sum := m.mat.Sum()
-	m.mu.RUnlock()
+// Order returns the matrix order (number or rows/cols).
+func (m *ConfusionMatrix) Order() (n int) {
+	m.shards.each(func(s *confusionState) { n = maxInt(n, s.size) })
+	return
+}
 
-	return sum
+// TotalWeight returns the total weight observed (sum of the matrix).
+func (m *ConfusionMatrix) TotalWeight() (sum float64) {
+	m.shards.each(func(s *confusionState) { sum += s.Sum() })
+	return
 }
 
 // Row returns the distribution of predicted weights for category x.
 func (m *ConfusionMatrix) Row(x int) []float64 {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	if x >= m.mat.size {
-		return nil
-	}
-
-	row := make([]float64, m.mat.size)
-	copy(row, m.mat.Row(x))
-	return row
+	return m.vector(x, (*confusionState).addRow)
 }
 
 // Column returns the distribution of actual weights for category x.
 func (m *ConfusionMatrix) Column(x int) []float64 {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	if x >= m.mat.size {
-		return nil
-	}
-
-	col := make([]float64, m.mat.size)
-	for i := 0; i < m.mat.size; i++ {
-		col[i] = m.mat.At(i, x)
-	}
-	return col
+	return m.vector(x, (*confusionState).addColumn)
 }
 
 // Accuracy returns the overall accuracy rate.
 func (m *ConfusionMatrix) Accuracy() float64 {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	sum := m.mat.Sum()
-	if sum == 0.0 {
-		return 0.0
-	}
-
-	var pos float64
-	for i := 0; i < m.mat.size; i++ {
-		pos += m.mat.At(i, i)
-	}
-	return pos / sum
+	var pos, sum float64
+	m.shards.each(func(s *confusionState) {
+		pos += s.trace()
+		sum += s.Sum()
+	})
+	return safeRatio(pos, sum)
 }
 
 // Precision calculates the positive predictive value for category x.
 func (m *ConfusionMatrix) Precision(x int) float64 {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	sum := m.mat.ColSum(x)
-	if sum == 0.0 {
-		return 0.0
-	}
-	return m.mat.At(x, x) / sum
+	pos, _, predicted := m.classSums(x)
+	return safeRatio(pos, predicted)
 }
 
 // Sensitivity calculates the recall (aka 'hit rate') for category x.
 func (m *ConfusionMatrix) Sensitivity(x int) float64 {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	sum := m.mat.RowSum(x)
-	if sum == 0.0 {
-		return 0.0
-	}
-	return m.mat.At(x, x) / sum
+	pos, actual, _ := m.classSums(x)
+	return safeRatio(pos, actual)
 }
 
 // F1 calculates the F1 score for category x, the harmonic mean of precision and sensitivity.
 func (m *ConfusionMatrix) F1(x int) float64 {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	csm := m.mat.ColSum(x)
-	if csm == 0 {
-		return 0
-	}
-
-	rsm := m.mat.RowSum(x)
-	if rsm == 0 {
-		return 0
-	}
-
-	pos := m.mat.At(x, x)
-	precision := pos / csm
-	sensitivity := pos / rsm
-	return 2 * precision * sensitivity / (precision + sensitivity)
+	return classF1(m.classSums(x))
 }
 
 // Kappa represents the Cohen's Kappa, a statistic which measures inter-rater agreement for qualitative
@@ -155,23 +156,7 @@ func (m *ConfusionMatrix) F1(x int) float64 {
 // calculation, as κ takes into account the possibility of the agreement occurring by chance.
 // https://en.wikipedia.org/wiki/Cohen%27s_kappa
 func (m *ConfusionMatrix) Kappa() float64 {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	sum := m.mat.Sum()
-	if sum == 0.0 {
-		return 0.0
-	}
-
-	var obs, exp float64
-	for i := 0; i < m.mat.size; i++ {
-		obs += m.mat.At(i, i)
-		exp += m.mat.RowSum(i) * m.mat.ColSum(i) / sum
-	}
-	if div := sum - exp; div != 0 {
-		return (obs - exp) / div
-	}
-	return 1.0
+	return m.merged().kappa()
 }
 
 // Matthews is a correlation coefficient used as a measure of the quality of binary
@@ -182,32 +167,278 @@ func (m *ConfusionMatrix) Kappa() float64 {
 // a perfect prediction, 0 an average random prediction and -1 an inverse prediction.
 // The statistic is also known as the phi coefficient. [source: Wikipedia]
 func (m *ConfusionMatrix) Matthews() float64 {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	return m.merged().matthews()
+}
+
+// Costs returns a copy of the attached cost matrix.
+func (m *ConfusionMatrix) Costs() CostMatrix {
+	return m.costs.clone()
+}
+
+// TotalCost calculates the total cost of all observations, as defined by the
+// attached cost matrix.
+func (m *ConfusionMatrix) TotalCost() float64 {
+	return m.merged().totalCost(m.costs)
+}
+
+// AverageCost calculates the average cost per unit of observed weight, as defined by
+// the attached cost matrix.
+func (m *ConfusionMatrix) AverageCost() float64 {
+	return m.merged().averageCost(m.costs)
+}
+
+// Snapshot returns a consistent point-in-time snapshot of all values. It merges the
+// shards only once and should be preferred over the individual accessors when
+// reading multiple values, e.g. the scores of all classes.
+func (m *ConfusionMatrix) Snapshot() ConfusionMatrixSnapshot {
+	snap := m.merged().snapshot(m.costs)
+	snap.Rejected = m.Rejected()
+	return snap
+}
+
+// SnapshotAndReset atomically returns a snapshot of all values and resets state.
+func (m *ConfusionMatrix) SnapshotAndReset() ConfusionMatrixSnapshot {
+	snap := m.harvest().snapshot(m.costs)
+	snap.Rejected = m.harvestRejected()
+	return snap
+}
+
+// merged returns the merged state of all shards.
+func (m *ConfusionMatrix) merged() *confusionState {
+	st := new(confusionState)
+	m.shards.each(st.merge)
+	return st
+}
+
+// classSums returns the weight of correct predictions of category x along with its
+// total actual (row) and predicted (column) weights across all shards.
+func (m *ConfusionMatrix) classSums(x int) (pos, actual, predicted float64) {
+	m.shards.each(func(s *confusionState) {
+		pos += s.At(x, x)
+		actual += s.RowSum(x)
+		predicted += s.ColSum(x)
+	})
+	return
+}
+
+// vector sums the row or column x of all shards, using add, into a vector of the
+// matrix order. Returns nil if x is out of range.
+func (m *ConfusionMatrix) vector(x int, add func(*confusionState, []float64, int) []float64) []float64 {
+	var vec []float64
+	var n int
+	m.shards.each(func(s *confusionState) {
+		n = maxInt(n, s.size)
+		vec = add(s, vec, x)
+	})
+	if x < 0 || x >= n {
+		return nil
+	}
+	for len(vec) < n {
+		vec = append(vec, 0)
+	}
+	return vec
+}
+
+// harvest returns the merged state of all shards and resets them.
+func (m *ConfusionMatrix) harvest() *confusionState {
+	st := new(confusionState)
+	m.shards.each(func(s *confusionState) {
+		st.merge(s)
+		*s = confusionState{}
+	})
+	return st
+}
+
+// confusionState contains the weights of actual (rows) vs predicted (columns)
+// categories.
+type confusionState struct {
+	resizableMatrix
+}
+
+func (s *confusionState) observe(actual, predicted int, weight float64) {
+	s.Set(actual, predicted, s.At(actual, predicted)+weight)
+}
+
+func (s *confusionState) merge(o *confusionState) {
+	s.resize(o.size)
+	for i := 0; i < o.size; i++ {
+		for j, v := range o.Row(i) {
+			s.data[i*s.size+j] += v
+		}
+	}
+}
+
+// addRow adds row x to vec, growing it as needed.
+func (s *confusionState) addRow(vec []float64, x int) []float64 {
+	row := s.Row(x)
+	for len(vec) < len(row) {
+		vec = append(vec, 0)
+	}
+	for j, v := range row {
+		vec[j] += v
+	}
+	return vec
+}
+
+// addColumn adds column x to vec, growing it as needed.
+func (s *confusionState) addColumn(vec []float64, x int) []float64 {
+	if x < 0 || x >= s.size {
+		return vec
+	}
+	for len(vec) < s.size {
+		vec = append(vec, 0)
+	}
+	for i := 0; i < s.size; i++ {
+		vec[i] += s.At(i, x)
+	}
+	return vec
+}
+
+// trace returns the sum of the diagonal, i.e. the weight of correct predictions.
+func (s *confusionState) trace() (sum float64) {
+	for i := 0; i < s.size; i++ {
+		sum += s.At(i, i)
+	}
+	return
+}
+
+func (s *confusionState) row(x int) []float64 {
+	if x >= s.size {
+		return nil
+	}
+
+	row := make([]float64, s.size)
+	copy(row, s.Row(x))
+	return row
+}
+
+func (s *confusionState) kappa() float64 {
+	return s.totals().kappa()
+}
+
+func (s *confusionState) matthews() float64 {
+	return s.totals().matthews()
+}
+
+func (s *confusionState) totalCost(costs CostMatrix) (cost float64) {
+	for i := 0; i < s.size; i++ {
+		for j := 0; j < s.size; j++ {
+			if w := s.At(i, j); w != 0 {
+				cost += w * costs.Cost(i, j)
+			}
+		}
+	}
+	return
+}
+
+func (s *confusionState) averageCost(costs CostMatrix) float64 {
+	return safeRatio(s.totalCost(costs), s.Sum())
+}
 
-	sum := m.mat.Sum()
-	if sum == 0.0 {
+// totals calculates the marginal sums in a single pass over the matrix.
+func (s *confusionState) totals() confusionTotals {
+	t := confusionTotals{
+		rows: make([]float64, s.size),
+		cols: make([]float64, s.size),
+	}
+	for i := 0; i < s.size; i++ {
+		for j, v := range s.Row(i) {
+			t.rows[i] += v
+			t.cols[j] += v
+			t.sum += v
+		}
+		t.trace += s.At(i, i)
+	}
+	return t
+}
+
+func (s *confusionState) snapshot(costs CostMatrix) ConfusionMatrixSnapshot {
+	t := s.totals()
+	cost := s.totalCost(costs)
+	snap := ConfusionMatrixSnapshot{
+		Weight:      t.sum,
+		Accuracy:    safeRatio(t.trace, t.sum),
+		Kappa:       t.kappa(),
+		Matthews:    t.matthews(),
+		Matrix:      make([][]float64, s.size),
+		Classes:     make([]ClassSnapshot, s.size),
+		TotalCost:   cost,
+		AverageCost: safeRatio(cost, t.sum),
+	}
+	for x := 0; x < s.size; x++ {
+		pos := s.At(x, x)
+		snap.Matrix[x] = s.row(x)
+		snap.Classes[x] = ClassSnapshot{
+			Precision:   safeRatio(pos, t.cols[x]),
+			Sensitivity: safeRatio(pos, t.rows[x]),
+			F1:          classF1(pos, t.rows[x], t.cols[x]),
+		}
+	}
+	return snap
+}
+
+// confusionTotals contains the marginal sums of a confusion matrix.
+type confusionTotals struct {
+	sum   float64   // total weight
+	trace float64   // weight of correct predictions
+	rows  []float64 // actual weights of each category
+	cols  []float64 // predicted weights of each category
+}
+
+func (t confusionTotals) kappa() float64 {
+	if t.sum == 0.0 {
 		return 0.0
 	}
 
-	var exp, cf1, cf2, cf3 float64
-	for i := 0; i < m.mat.size; i++ {
-		rsum := m.mat.RowSum(i)
-		csum := m.mat.ColSum(i)
+	var exp float64
+	for i := range t.rows {
+		exp += t.rows[i] * t.cols[i] / t.sum
+	}
+	if div := t.sum - exp; div != 0 {
+		return (t.trace - exp) / div
+	}
+	return 1.0
+}
+
+func (t confusionTotals) matthews() float64 {
+	if t.sum == 0.0 {
+		return 0.0
+	}
 
-		exp += m.mat.At(i, i)
-		cf1 += rsum * csum
-		cf2 += rsum * rsum
-		cf3 += csum * csum
+	var cf1, cf2, cf3 float64
+	for i := range t.rows {
+		cf1 += t.rows[i] * t.cols[i]
+		cf2 += t.rows[i] * t.rows[i]
+		cf3 += t.cols[i] * t.cols[i]
 	}
 
-	sum2 := sum * sum
+	sum2 := t.sum * t.sum
 	if pdt := (sum2 - cf2) * (sum2 - cf3); pdt != 0 {
-		return ((exp * sum) - cf1) / math.Sqrt(pdt)
+		return ((t.trace * t.sum) - cf1) / math.Sqrt(pdt)
 	}
 	return 0
 }
 
+// classF1 calculates the F1 score of a category from the weight of its correct
+// predictions and its total actual and predicted weights.
+func classF1(pos, actual, predicted float64) float64 {
+	if actual == 0 || predicted == 0 {
+		return 0
+	}
+
+	precision := pos / predicted
+	sensitivity := pos / actual
+	return 2 * precision * sensitivity / (precision + sensitivity)
+}
+
+// safeRatio returns n / d, or 0 if d is 0.
+func safeRatio(n, d float64) float64 {
+	if d == 0 {
+		return 0
+	}
+	return n / d
+}
+
 type resizableMatrix struct {
 	size int
 	data []float64
@@ -257,6 +488,16 @@ func (m *resizableMatrix) ColSum(j int) (sum float64) {
 	return
 }
 
+// Copy returns a copy of the matrix.
+func (m *resizableMatrix) Copy() resizableMatrix {
+	c := resizableMatrix{size: m.size}
+	if m.data != nil {
+		c.data = make([]float64, len(m.data))
+		copy(c.data, m.data)
+	}
+	return c
+}
+
 // Sum calculates the sum of all cells.
 func (m *resizableMatrix) Sum() float64 {
 	sum := 0.0
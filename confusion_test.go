@@ -2,6 +2,7 @@ package mlmetrics_test
 
 import (
 	"fmt"
+	"runtime"
 	"testing"
 
 	. "github.com/bsm/ginkgo"
@@ -94,6 +95,28 @@ var _ = Describe("ConfusionMatrix", func() {
 		Expect(subject.Accuracy()).To(BeNumerically("~", 0.880, 0.001))
 	})
 
+	It("should calculate costs", func() {
+		costs := mlmetrics.CostMatrix{
+			{0, 1},
+			{100, 0},
+		}
+		subject = mlmetrics.NewConfusionMatrixWithCosts(costs)
+		costs[1][0] = 1
+		subject.Costs()[1][0] = 1
+		subject.ObserveWeight(0, 0, 90)
+		subject.ObserveWeight(0, 1, 5)
+		subject.ObserveWeight(1, 0, 2)
+		subject.ObserveWeight(1, 1, 3)
+		Expect(subject.Costs()).To(HaveLen(2))
+		Expect(subject.TotalCost()).To(BeNumerically("~", 205.0, 0.001))
+		Expect(subject.AverageCost()).To(BeNumerically("~", 2.05, 0.001))
+
+		subject.Reset()
+		Expect(subject.Costs()).To(HaveLen(2))
+		Expect(subject.TotalCost()).To(Equal(0.0))
+		Expect(subject.AverageCost()).To(Equal(0.0))
+	})
+
 	Describe("Kappa", func() {
 		// These label vectors reproduce the contingency matrix from Artstein and
 		// Poesio (2008), Table 1.
@@ -217,6 +240,68 @@ var _ = Describe("ConfusionMatrix", func() {
 			Expect(subject.Matthews()).To(Equal(0.0))
 		})
 	})
+
+	It("should snapshot", func() {
+		y1 := []int{0, 0, 1, 0, 0, 1, 1, 2}
+		y2 := []int{1, 0, 1, 0, 0, 0, 2, 2}
+		for i := range y1 {
+			subject.Observe(y1[i], y2[i])
+		}
+
+		snap := subject.Snapshot()
+		Expect(snap.Weight).To(Equal(subject.TotalWeight()))
+		Expect(snap.Accuracy).To(Equal(subject.Accuracy()))
+		Expect(snap.Kappa).To(Equal(subject.Kappa()))
+		Expect(snap.Matthews).To(Equal(subject.Matthews()))
+		Expect(snap.Matrix).To(HaveLen(subject.Order()))
+		Expect(snap.Classes).To(HaveLen(subject.Order()))
+		for x := 0; x < subject.Order(); x++ {
+			Expect(snap.Matrix[x]).To(Equal(subject.Row(x)))
+			Expect(snap.Classes[x]).To(Equal(mlmetrics.ClassSnapshot{
+				Precision:   subject.Precision(x),
+				Sensitivity: subject.Sensitivity(x),
+				F1:          subject.F1(x),
+			}))
+		}
+
+		row := subject.Row(0)
+		subject.Observe(0, 0)
+		Expect(snap.Matrix[0]).To(Equal(row))
+	})
+
+	It("should merge concurrent observations", func() {
+		expected := mlmetrics.NewConfusionMatrix()
+		for i := 0; i < 1000; i++ {
+			expected.Observe(i%3, i%5%3)
+		}
+
+		observeConcurrently(1000, func(i int) {
+			subject.Observe(i%3, i%5%3)
+		})
+		Expect(subject.Snapshot()).To(equalSnapshot(expected.Snapshot()))
+	})
+
+	It("should observe batches", func() {
+		expected := mlmetrics.NewConfusionMatrix()
+		expected.Observe(0, 0)
+		expected.Observe(0, 1)
+		expected.ObserveWeight(2, 1, 3)
+
+		Expect(subject.ObserveBatch([]int{0, 0, 2, -1}, []int{0, 1, 1, 0}, []float64{1, 1, 3, 1})).To(MatchError(mlmetrics.ErrInvalidCategory))
+		Expect(subject.Snapshot()).To(equalSnapshot(expected.Snapshot()))
+		Expect(subject.ObserveBatch([]int{0}, nil, nil)).To(MatchError(mlmetrics.ErrLengthMismatch))
+	})
+
+	It("should observe batches of any integer type", func() {
+		expected := mlmetrics.NewConfusionMatrix()
+		expected.Observe(0, 0)
+		expected.Observe(0, 1)
+		expected.ObserveWeight(2, 1, 3)
+
+		Expect(mlmetrics.ObserveConfusionMatrixBatch(subject, []int32{0, 0, 2, -1}, []uint8{0, 1, 1, 0}, []float64{1, 1, 3, 1})).To(MatchError(mlmetrics.ErrInvalidCategory))
+		Expect(subject.Snapshot()).To(equalSnapshot(expected.Snapshot()))
+		Expect(subject.Rejected()).To(Equal(mlmetrics.Rejections{InvalidCategory: 1}))
+	})
 })
 
 func ExampleConfusionMatrix() {
@@ -280,3 +365,46 @@ func BenchmarkConfusionMatrix_Matthews(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkConfusionMatrix_ObserveParallel(b *testing.B) {
+	cm := mlmetrics.NewConfusionMatrix()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			cm.Observe(i%3, i%5%3)
+		}
+	})
+}
+
+func BenchmarkConfusionMatrix_Snapshot(b *testing.B) {
+	benchmarkConfusionMatrixRead(b, func(cm *mlmetrics.ConfusionMatrix) {
+		cm.Snapshot()
+	})
+}
+
+func BenchmarkConfusionMatrix_Precision(b *testing.B) {
+	benchmarkConfusionMatrixRead(b, func(cm *mlmetrics.ConfusionMatrix) {
+		cm.Precision(3)
+	})
+}
+
+// benchmarkConfusionMatrixRead benchmarks reads from a 10-class matrix at various
+// numbers of processors, and thus shards.
+func benchmarkConfusionMatrixRead(b *testing.B, read func(*mlmetrics.ConfusionMatrix)) {
+	for _, procs := range []int{1, 4, 8} {
+		b.Run(fmt.Sprintf("procs=%d", procs), func(b *testing.B) {
+			defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+
+			cm := mlmetrics.NewConfusionMatrix()
+			cm.Reset() // allocate shards for procs before observing
+			observeConcurrently(10000, func(i int) {
+				cm.Observe(i%10, i%7)
+			})
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				read(cm)
+			}
+		})
+	}
+}
@@ -0,0 +1,399 @@
+package mlmetrics
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// Correlation measures the correlation between actual and predicted values of
+// a regression model. Pearson's coefficient is calculated in a streaming fashion,
+// Spearman's and Kendall's rank coefficients require observations to be retained.
+// By default, all observations are retained and memory grows linearly with their
+// number, use NewCorrelationWithSampleSize to bound it for long-running streams.
+type Correlation struct {
+	rejector
+
+	weight float64 // total weight observed
+	meanX  float64 // mean of actual values
+	meanY  float64 // mean of predicted values
+	sumXX  float64 // sum of squared deviations of actual values
+	sumYY  float64 // sum of squared deviations of predicted values
+	sumXY  float64 // sum of co-deviations
+
+	size   int             // maximum sample size, 0 for unlimited
+	sample correlationHeap // retained observations
+	rnd    *rand.Rand
+
+	mu sync.RWMutex
+}
+
+// CorrelationSnapshot is a point-in-time snapshot of a Correlation metric.
+type CorrelationSnapshot struct {
+	Weight   float64    // total weight observed
+	Pearson  float64    // Pearson correlation coefficient
+	Spearman float64    // Spearman rank correlation coefficient
+	Kendall  float64    // Kendall rank correlation coefficient
+	Rejected Rejections // observations rejected by validation
+}
+
+// NewCorrelation inits a new metric that retains all observations and calculates
+// exact rank coefficients. Memory usage is O(n) in the number of observations
+// until the metric is Reset.
+func NewCorrelation() *Correlation {
+	return NewCorrelationWithSampleSize(0)
+}
+
+// NewCorrelationWithSampleSize inits a new metric with bounded memory. Rank
+// coefficients are approximated from a weighted random sample of at most size
+// observations. Pearson's coefficient remains exact. A size of 0 retains all
+// observations.
+func NewCorrelationWithSampleSize(size int) *Correlation {
+	if size < 0 {
+		size = 0
+	}
+	return &Correlation{size: size, rnd: rand.New(rand.NewSource(1))}
+}
+
+// Reset resets state.
+func (m *Correlation) Reset() {
+	m.mu.Lock()
+	m.reset()
+	m.mu.Unlock()
+	m.resetRejected()
+}
+
+// Observe records an observation of the actual vs the predicted value.
+func (m *Correlation) Observe(actual, predicted float64) {
+	m.ObserveWeight(actual, predicted, 1.0)
+}
+
+// ObserveWeight records an observation of the actual vs the predicted value with a given weight.
+func (m *Correlation) ObserveWeight(actual, predicted, weight float64) {
+	_ = m.ObserveWeightStrict(actual, predicted, weight)
+}
+
+// ObserveWeightStrict is like ObserveWeight, but returns an error if the observation was
+// rejected by validation, see Rejected.
+func (m *Correlation) ObserveWeightStrict(actual, predicted, weight float64) error {
+	if err := validateNumerics(actual, predicted, weight); err != nil {
+		return m.reject(err)
+	}
+
+	m.mu.Lock()
+	m.observe(actual, predicted, weight)
+	m.mu.Unlock()
+	return nil
+}
+
+// ObserveBatch records a batch of observations of actual vs predicted values.
+// Weights are optional, if given, they must match the number of observations.
+// Returns ErrLengthMismatch if the lengths of the inputs differ and a *BatchError
+// if observations were rejected.
+func (m *Correlation) ObserveBatch(actual, predicted []float64, weights []float64) error {
+	if err := validateBatch(len(actual), weights, len(predicted)); err != nil {
+		return m.rejectBatch(len(actual), err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var berr BatchError
+	for i := range actual {
+		weight := batchWeight(weights, i)
+		if err := validateNumerics(actual[i], predicted[i], weight); err != nil {
+			berr.add(i, m.reject(err))
+			continue
+		}
+		m.observe(actual[i], predicted[i], weight)
+	}
+	return berr.errOrNil()
+}
+
+// TotalWeight returns the total weight observed.
+func (m *Correlation) TotalWeight() float64 {
+	m.mu.RLock()
+	weight := m.weight
+	m.mu.RUnlock()
+	return weight
+}
+
+// Pearson calculates Pearson's product-moment correlation coefficient.
+// https://en.wikipedia.org/wiki/Pearson_correlation_coefficient
+func (m *Correlation) Pearson() float64 {
+	m.mu.RLock()
+	sumXX, sumYY, sumXY := m.sumXX, m.sumYY, m.sumXY
+	m.mu.RUnlock()
+
+	if pdt := sumXX * sumYY; pdt > 0 {
+		return sumXY / math.Sqrt(pdt)
+	}
+	return 0.0
+}
+
+// Spearman calculates Spearman's rank correlation coefficient. Tied values
+// are assigned the mean of their ranks.
+// https://en.wikipedia.org/wiki/Spearman%27s_rank_correlation_coefficient
+func (m *Correlation) Spearman() float64 {
+	m.mu.RLock()
+	points := m.sample.Copy()
+	m.mu.RUnlock()
+
+	if len(points) < 2 {
+		return 0.0
+	}
+
+	rx := points.Ranks(func(p correlationPoint) float64 { return p.X })
+	ry := points.Ranks(func(p correlationPoint) float64 { return p.Y })
+
+	var weight, meanX, meanY, sumXX, sumYY, sumXY float64
+	for i, p := range points {
+		weight += p.W
+		dx := rx[i] - meanX
+		dy := ry[i] - meanY
+		meanX += dx * p.W / weight
+		meanY += dy * p.W / weight
+		sumXX += p.W * dx * (rx[i] - meanX)
+		sumYY += p.W * dy * (ry[i] - meanY)
+		sumXY += p.W * dx * (ry[i] - meanY)
+	}
+
+	if pdt := sumXX * sumYY; pdt > 0 {
+		return sumXY / math.Sqrt(pdt)
+	}
+	return 0.0
+}
+
+// Kendall calculates Kendall's tau-b rank correlation coefficient, which
+// accounts for ties.
+// https://en.wikipedia.org/wiki/Kendall_rank_correlation_coefficient
+func (m *Correlation) Kendall() float64 {
+	m.mu.RLock()
+	points := m.sample.Copy()
+	m.mu.RUnlock()
+
+	if len(points) < 2 {
+		return 0.0
+	}
+
+	// compress predicted values into ranks
+	ys := make([]float64, len(points))
+	for i, p := range points {
+		ys[i] = p.Y
+	}
+	sort.Float64s(ys)
+	ys = uniqueFloat64s(ys)
+
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].X == points[j].X {
+			return points[i].Y < points[j].Y
+		}
+		return points[i].X < points[j].X
+	})
+
+	// total weight, and pair weights tied on actual and predicted values
+	var total, sumW2, tiedX float64
+	tiedY := make(map[float64][2]float64, len(ys))
+
+	// balance of concordant vs discordant pair weights
+	var balance float64
+	tree := make(fenwickTree, len(ys)+1)
+
+	for i := 0; i < len(points); {
+		// find group of observations with the same actual value
+		j := i
+		var gw, gw2 float64
+		for ; j < len(points) && points[j].X == points[i].X; j++ {
+			gw += points[j].W
+			gw2 += points[j].W * points[j].W
+		}
+		tiedX += (gw*gw - gw2) / 2
+
+		for k := i; k < j; k++ {
+			p := points[k]
+			rank := sort.SearchFloat64s(ys, p.Y) + 1
+			below := tree.Sum(rank - 1)
+			above := tree.Sum(len(ys)) - tree.Sum(rank)
+			balance += p.W * (below - above)
+
+			total += p.W
+			sumW2 += p.W * p.W
+			tw := tiedY[p.Y]
+			tiedY[p.Y] = [2]float64{tw[0] + p.W, tw[1] + p.W*p.W}
+		}
+		for k := i; k < j; k++ {
+			tree.Add(sort.SearchFloat64s(ys, points[k].Y)+1, points[k].W)
+		}
+		i = j
+	}
+
+	var tiedYSum float64
+	for _, tw := range tiedY {
+		tiedYSum += (tw[0]*tw[0] - tw[1]) / 2
+	}
+	pairs := (total*total - sumW2) / 2
+
+	if pdt := (pairs - tiedX) * (pairs - tiedYSum); pdt > 0 {
+		return balance / math.Sqrt(pdt)
+	}
+	return 0.0
+}
+
+// Snapshot returns a consistent point-in-time snapshot of all values.
+func (m *Correlation) Snapshot() CorrelationSnapshot {
+	m.mu.RLock()
+	c := m.clone()
+	m.mu.RUnlock()
+
+	snap := c.snapshot()
+	snap.Rejected = m.Rejected()
+	return snap
+}
+
+// SnapshotAndReset atomically returns a snapshot of all values and resets state.
+func (m *Correlation) SnapshotAndReset() CorrelationSnapshot {
+	m.mu.Lock()
+	c := m.clone()
+	m.reset()
+	m.mu.Unlock()
+
+	snap := c.snapshot()
+	snap.Rejected = m.harvestRejected()
+	return snap
+}
+
+func (m *Correlation) observe(actual, predicted, weight float64) {
+	m.weight += weight
+	dx := actual - m.meanX
+	dy := predicted - m.meanY
+	m.meanX += dx * weight / m.weight
+	m.meanY += dy * weight / m.weight
+	m.sumXX += weight * dx * (actual - m.meanX)
+	m.sumYY += weight * dy * (predicted - m.meanY)
+	m.sumXY += weight * dx * (predicted - m.meanY)
+
+	if m.size == 0 {
+		m.sample = append(m.sample, correlationPoint{X: actual, Y: predicted, W: weight})
+		return
+	}
+
+	// Efraimidis-Spirakis weighted reservoir sampling, the retained
+	// observations are unweighted as they were sampled proportional to weight
+	key := math.Log(m.rnd.Float64()) / weight
+	if len(m.sample) < m.size {
+		heap.Push(&m.sample, correlationPoint{X: actual, Y: predicted, W: 1, key: key})
+	} else if key > m.sample[0].key {
+		m.sample[0] = correlationPoint{X: actual, Y: predicted, W: 1, key: key}
+		heap.Fix(&m.sample, 0)
+	}
+}
+
+func (m *Correlation) clone() *Correlation {
+	return &Correlation{
+		weight: m.weight,
+		meanX:  m.meanX,
+		meanY:  m.meanY,
+		sumXX:  m.sumXX,
+		sumYY:  m.sumYY,
+		sumXY:  m.sumXY,
+		size:   m.size,
+		sample: m.sample.Copy(),
+	}
+}
+
+func (m *Correlation) reset() {
+	m.weight = 0
+	m.meanX = 0
+	m.meanY = 0
+	m.sumXX = 0
+	m.sumYY = 0
+	m.sumXY = 0
+	m.sample = m.sample[:0]
+}
+
+func (m *Correlation) snapshot() CorrelationSnapshot {
+	return CorrelationSnapshot{
+		Weight:   m.TotalWeight(),
+		Pearson:  m.Pearson(),
+		Spearman: m.Spearman(),
+		Kendall:  m.Kendall(),
+	}
+}
+
+// --------------------------------------------------------------------
+
+type correlationPoint struct {
+	X, Y, W float64
+	key     float64
+}
+
+type correlationHeap []correlationPoint
+
+func (h correlationHeap) Len() int            { return len(h) }
+func (h correlationHeap) Less(i, j int) bool  { return h[i].key < h[j].key }
+func (h correlationHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *correlationHeap) Push(x interface{}) { *h = append(*h, x.(correlationPoint)) }
+func (h *correlationHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// Copy returns a copy of the points.
+func (h correlationHeap) Copy() correlationHeap {
+	if len(h) == 0 {
+		return nil
+	}
+	return append(make(correlationHeap, 0, len(h)), h...)
+}
+
+// Ranks returns the weighted mid-ranks of the points by a given value, in
+// the order of the points.
+func (h correlationHeap) Ranks(value func(correlationPoint) float64) []float64 {
+	index := make([]int, len(h))
+	for i := range index {
+		index[i] = i
+	}
+	sort.Slice(index, func(i, j int) bool { return value(h[index[i]]) < value(h[index[j]]) })
+
+	ranks := make([]float64, len(h))
+	cum := 0.0
+	for i := 0; i < len(index); {
+		v := value(h[index[i]])
+		j := i
+		gw := 0.0
+		for ; j < len(index) && value(h[index[j]]) == v; j++ {
+			gw += h[index[j]].W
+		}
+		for k := i; k < j; k++ {
+			ranks[index[k]] = cum + gw/2
+		}
+		cum += gw
+		i = j
+	}
+	return ranks
+}
+
+// --------------------------------------------------------------------
+
+// fenwickTree is a binary indexed tree of weights, indexed from 1.
+type fenwickTree []float64
+
+// Add adds weight w at index i.
+func (t fenwickTree) Add(i int, w float64) {
+	for ; i < len(t); i += i & -i {
+		t[i] += w
+	}
+}
+
+// Sum returns the sum of weights at indices 1..i.
+func (t fenwickTree) Sum(i int) (sum float64) {
+	for ; i > 0; i -= i & -i {
+		sum += t[i]
+	}
+	return
+}
@@ -0,0 +1,117 @@
+package mlmetrics_test
+
+import (
+	"math"
+	"math/rand"
+
+	. "github.com/bsm/ginkgo"
+	. "github.com/bsm/gomega"
+	"github.com/bsm/mlmetrics"
+)
+
+var _ = Describe("Correlation", func() {
+	var subject *mlmetrics.Correlation
+
+	observe := func(actual, predicted []float64) {
+		for i := range actual {
+			subject.Observe(actual[i], predicted[i])
+		}
+	}
+
+	BeforeEach(func() {
+		subject = mlmetrics.NewCorrelation()
+	})
+
+	It("should calculate Pearson", func() {
+		observe([]float64{1, 2, 3, 4, 5}, []float64{10, 9, 2.5, 6, 4})
+		Expect(subject.TotalWeight()).To(Equal(5.0))
+		Expect(subject.Pearson()).To(BeNumerically("~", -0.743, 0.001))
+	})
+
+	It("should calculate Spearman", func() {
+		observe([]float64{1, 2, 3, 4, 5}, []float64{5, 6, 7, 8, 7})
+		Expect(subject.Spearman()).To(BeNumerically("~", 0.821, 0.001))
+	})
+
+	It("should calculate Kendall", func() {
+		observe([]float64{12, 2, 1, 12, 2}, []float64{1, 4, 7, 1, 0})
+		Expect(subject.Kendall()).To(BeNumerically("~", -0.471, 0.001))
+	})
+
+	It("should treat weights as repeated observations", func() {
+		subject.ObserveWeight(1, 2, 2.0)
+		subject.ObserveWeight(2, 1, 1.0)
+		subject.ObserveWeight(3, 4, 3.0)
+		subject.ObserveWeight(4, 3, 1.0)
+
+		expected := mlmetrics.NewCorrelation()
+		for _, p := range [][2]float64{{1, 2}, {1, 2}, {2, 1}, {3, 4}, {3, 4}, {3, 4}, {4, 3}} {
+			expected.Observe(p[0], p[1])
+		}
+
+		Expect(subject.TotalWeight()).To(Equal(7.0))
+		Expect(subject.Pearson()).To(BeNumerically("~", expected.Pearson(), 1e-9))
+		Expect(subject.Spearman()).To(BeNumerically("~", expected.Spearman(), 1e-9))
+		Expect(subject.Kendall()).To(BeNumerically("~", expected.Kendall(), 1e-9))
+	})
+
+	It("should calculate perfect correlation", func() {
+		observe([]float64{1, 2, 3, 4}, []float64{1, 4, 9, 16})
+		Expect(subject.Pearson()).To(BeNumerically("~", 0.984, 0.001))
+		Expect(subject.Spearman()).To(BeNumerically("~", 1.0, 0.001))
+		Expect(subject.Kendall()).To(BeNumerically("~", 1.0, 0.001))
+	})
+
+	It("should approximate with bounded memory", func() {
+		subject = mlmetrics.NewCorrelationWithSampleSize(200)
+		exact := mlmetrics.NewCorrelation()
+
+		rnd := rand.New(rand.NewSource(33))
+		for i := 0; i < 10000; i++ {
+			x := rnd.Float64()
+			y := x + rnd.NormFloat64()*0.2
+			subject.Observe(x, y)
+			exact.Observe(x, y)
+		}
+
+		Expect(subject.Pearson()).To(Equal(exact.Pearson()))
+		Expect(subject.Spearman()).To(BeNumerically("~", exact.Spearman(), 0.05))
+		Expect(subject.Kendall()).To(BeNumerically("~", exact.Kendall(), 0.05))
+	})
+
+	It("should handle blanks", func() {
+		Expect(subject.Pearson()).To(Equal(0.0))
+		Expect(subject.Spearman()).To(Equal(0.0))
+		Expect(subject.Kendall()).To(Equal(0.0))
+
+		subject.Observe(1, 1)
+		subject.Observe(2, 1)
+		Expect(subject.Pearson()).To(Equal(0.0))
+		Expect(subject.Spearman()).To(Equal(0.0))
+		Expect(subject.Kendall()).To(Equal(0.0))
+
+		subject.Reset()
+		Expect(subject.TotalWeight()).To(Equal(0.0))
+		Expect(subject.Kendall()).To(Equal(0.0))
+	})
+
+	It("should not lose observations when harvesting concurrently", func() {
+		total := harvestConcurrently(4000, func(i int) {
+			subject.Observe(float64(i), float64(i%7))
+		}, func() float64 {
+			return subject.SnapshotAndReset().Weight
+		})
+		Expect(total).To(Equal(4000.0))
+	})
+
+	It("should observe batches", func() {
+		expected := mlmetrics.NewCorrelation()
+		expected.Observe(1, 10)
+		expected.Observe(2, 9)
+		expected.ObserveWeight(3, 2.5, 2)
+
+		Expect(subject.ObserveBatch([]float64{1, 2, 3, math.NaN()}, []float64{10, 9, 2.5, 1}, []float64{1, 1, 2, 1})).To(MatchError(mlmetrics.ErrInvalidNumeric))
+		Expect(subject.Snapshot()).To(equalSnapshot(expected.Snapshot()))
+		Expect(subject.ObserveBatch([]float64{1}, []float64{1}, []float64{})).To(MatchError(mlmetrics.ErrLengthMismatch))
+	})
+})
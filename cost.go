@@ -0,0 +1,61 @@
+package mlmetrics
+
+import "math"
+
+// CostMatrix contains the costs of predictions, indexed by the actual and then
+// the predicted category, i.e. costs[actual][predicted]. Correct predictions
+// typically have a cost of 0. Utilities (gains) can be expressed as negative costs.
+type CostMatrix [][]float64
+
+// Cost returns the cost of predicting category predicted when the actual category
+// was actual. Returns 0 for categories outside the matrix.
+func (c CostMatrix) Cost(actual, predicted int) float64 {
+	if actual < 0 || actual >= len(c) {
+		return 0
+	}
+	if row := c[actual]; predicted >= 0 && predicted < len(row) {
+		return row[predicted]
+	}
+	return 0
+}
+
+// ExpectedCost calculates the expected cost of predicting category predicted,
+// given the probabilities of each actual category.
+func (c CostMatrix) ExpectedCost(probs []float64, predicted int) float64 {
+	var sum float64
+	for actual, p := range probs {
+		sum += p * c.Cost(actual, predicted)
+	}
+	return sum
+}
+
+// Decide returns the cost-optimal prediction given the probabilities of each actual
+// category, i.e. the category with the lowest expected cost. Returns -1 if the
+// matrix is empty.
+func (c CostMatrix) Decide(probs []float64) int {
+	size := len(c)
+	for _, row := range c {
+		size = maxInt(size, len(row))
+	}
+
+	best, lowest := -1, math.Inf(1)
+	for predicted := 0; predicted < size; predicted++ {
+		if cost := c.ExpectedCost(probs, predicted); cost < lowest {
+			best, lowest = predicted, cost
+		}
+	}
+	return best
+}
+
+// clone returns a deep copy of the matrix.
+func (c CostMatrix) clone() CostMatrix {
+	if c == nil {
+		return nil
+	}
+
+	dup := make(CostMatrix, len(c))
+	for i, row := range c {
+		dup[i] = append([]float64(nil), row...)
+	}
+	return dup
+}
@@ -0,0 +1,42 @@
+package mlmetrics_test
+
+import (
+	. "github.com/bsm/ginkgo"
+	. "github.com/bsm/gomega"
+	"github.com/bsm/mlmetrics"
+)
+
+var _ = Describe("CostMatrix", func() {
+	subject := mlmetrics.CostMatrix{
+		{0, 1},
+		{100, 0},
+	}
+
+	It("should return costs", func() {
+		Expect(subject.Cost(0, 1)).To(Equal(1.0))
+		Expect(subject.Cost(1, 0)).To(Equal(100.0))
+		Expect(subject.Cost(2, 0)).To(Equal(0.0))
+		Expect(subject.Cost(0, -1)).To(Equal(0.0))
+	})
+
+	It("should calculate expected costs", func() {
+		Expect(subject.ExpectedCost([]float64{0.98, 0.02}, 0)).To(BeNumerically("~", 2.0, 0.001))
+		Expect(subject.ExpectedCost([]float64{0.98, 0.02}, 1)).To(BeNumerically("~", 0.98, 0.001))
+	})
+
+	It("should decide", func() {
+		Expect(subject.Decide([]float64{0.98, 0.02})).To(Equal(1))
+		Expect(subject.Decide([]float64{0.995, 0.005})).To(Equal(0))
+		Expect(mlmetrics.CostMatrix{}.Decide([]float64{1})).To(Equal(-1))
+	})
+
+	It("should decide with utilities", func() {
+		utility := mlmetrics.CostMatrix{
+			{-1, 0, 0},
+			{0, -2, 0},
+			{0, 0, -5},
+		}
+		Expect(utility.Decide([]float64{0.5, 0.3, 0.2})).To(Equal(2))
+		Expect(utility.Decide([]float64{0.5, 0.4, 0.1})).To(Equal(1))
+	})
+})
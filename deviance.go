@@ -0,0 +1,410 @@
+package mlmetrics
+
+import (
+	"math"
+	"sync"
+)
+
+// Deviance is the mean Tweedie deviance, a regression metric for models with
+// an objective of the Tweedie family, such as Poisson or Gamma regressions.
+// https://en.wikipedia.org/wiki/Tweedie_distribution#The_Tweedie_deviance
+type Deviance struct {
+	rejector
+
+	power float64
+
+	weight float64 // total weight observed
+	devSum float64 // weighted sum of unit deviances
+
+	mu sync.RWMutex
+}
+
+// DevianceSnapshot is a point-in-time snapshot of a Deviance metric.
+type DevianceSnapshot struct {
+	Power       float64    // Tweedie power
+	Weight      float64    // total weight observed
+	Score       float64    // mean deviance
+	OutOfDomain int        // number of observations outside the valid domain
+	Rejected    Rejections // observations rejected by validation
+}
+
+// NewPoissonDeviance inits a mean Poisson deviance metric (power 1). Actual values must be
+// non-negative and predicted values must be positive.
+func NewPoissonDeviance() *Deviance {
+	return NewTweedieDeviance(1)
+}
+
+// NewGammaDeviance inits a mean Gamma deviance metric (power 2). Actual and predicted values
+// must be positive.
+func NewGammaDeviance() *Deviance {
+	return NewTweedieDeviance(2)
+}
+
+// NewTweedieDeviance inits a mean Tweedie deviance metric with a given power:
+//
+//	power < 0      : extreme stable, predicted values must be positive
+//	power = 0      : normal, equivalent to the mean squared error
+//	1 <= power < 2 : compound Poisson, actual values must be non-negative and predicted values positive
+//	power >= 2     : Gamma, inverse Gaussian and others, actual and predicted values must be positive
+//
+// Tweedie distributions are not defined for powers between 0 and 1. Such powers,
+// as well as NaN, fall back to the default power of 0.
+func NewTweedieDeviance(power float64) *Deviance {
+	if power > 0 && power < 1 || math.IsNaN(power) {
+		power = 0
+	}
+	return &Deviance{power: power}
+}
+
+// Power returns the Tweedie power.
+func (m *Deviance) Power() float64 {
+	return m.power
+}
+
+// Reset resets state.
+func (m *Deviance) Reset() {
+	m.mu.Lock()
+	m.reset()
+	m.mu.Unlock()
+	m.resetRejected()
+}
+
+// Observe records an observation of the actual vs the predicted value.
+func (m *Deviance) Observe(actual, predicted float64) {
+	m.ObserveWeight(actual, predicted, 1.0)
+}
+
+// ObserveWeight records an observation of the actual vs the predicted value with a given weight.
+// Observations outside the valid domain of the distribution are not included in the score
+// but are counted instead, see OutOfDomain.
+func (m *Deviance) ObserveWeight(actual, predicted, weight float64) {
+	_ = m.ObserveWeightStrict(actual, predicted, weight)
+}
+
+// ObserveWeightStrict is like ObserveWeight, but returns an error if the observation was
+// rejected by validation, see Rejected.
+func (m *Deviance) ObserveWeightStrict(actual, predicted, weight float64) error {
+	if err := m.validate(actual, predicted, weight); err != nil {
+		return m.reject(err)
+	}
+
+	m.mu.Lock()
+	m.observe(actual, predicted, weight)
+	m.mu.Unlock()
+	return nil
+}
+
+// ObserveBatch records a batch of observations of actual vs predicted values.
+// Weights are optional, if given, they must match the number of observations.
+// Returns ErrLengthMismatch if the lengths of the inputs differ and a *BatchError
+// if observations were rejected.
+func (m *Deviance) ObserveBatch(actual, predicted []float64, weights []float64) error {
+	if err := validateBatch(len(actual), weights, len(predicted)); err != nil {
+		return m.rejectBatch(len(actual), err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var berr BatchError
+	for i := range actual {
+		weight := batchWeight(weights, i)
+		if err := m.validate(actual[i], predicted[i], weight); err != nil {
+			berr.add(i, m.reject(err))
+			continue
+		}
+		m.observe(actual[i], predicted[i], weight)
+	}
+	return berr.errOrNil()
+}
+
+// TotalWeight returns the total weight observed.
+func (m *Deviance) TotalWeight() float64 {
+	m.mu.RLock()
+	weight := m.weight
+	m.mu.RUnlock()
+	return weight
+}
+
+// OutOfDomain returns the number of observations which were not recorded because the
+// actual or predicted values were outside the valid domain, see Rejected.
+func (m *Deviance) OutOfDomain() int {
+	return int(m.Rejected().OutOfDomain)
+}
+
+// Score calculates the mean deviance.
+func (m *Deviance) Score() float64 {
+	m.mu.RLock()
+	weight := m.weight
+	devSum := m.devSum
+	m.mu.RUnlock()
+
+	if weight > 0 {
+		return devSum / weight
+	}
+	return 0.0
+}
+
+// Snapshot returns a consistent point-in-time snapshot of all values.
+func (m *Deviance) Snapshot() DevianceSnapshot {
+	m.mu.RLock()
+	c := m.clone()
+	m.mu.RUnlock()
+
+	snap := c.snapshot()
+	snap.Rejected = m.Rejected()
+	snap.OutOfDomain = int(snap.Rejected.OutOfDomain)
+	return snap
+}
+
+// SnapshotAndReset atomically returns a snapshot of all values and resets state.
+func (m *Deviance) SnapshotAndReset() DevianceSnapshot {
+	m.mu.Lock()
+	c := m.clone()
+	m.reset()
+	m.mu.Unlock()
+
+	snap := c.snapshot()
+	snap.Rejected = m.harvestRejected()
+	snap.OutOfDomain = int(snap.Rejected.OutOfDomain)
+	return snap
+}
+
+func (m *Deviance) observe(actual, predicted, weight float64) {
+	m.weight += weight
+	m.devSum += m.unitDeviance(actual, predicted) * weight
+}
+
+func (m *Deviance) clone() *Deviance {
+	return &Deviance{
+		power:  m.power,
+		weight: m.weight,
+		devSum: m.devSum,
+	}
+}
+
+func (m *Deviance) reset() {
+	m.weight = 0
+	m.devSum = 0
+}
+
+func (m *Deviance) snapshot() DevianceSnapshot {
+	return DevianceSnapshot{
+		Power:  m.Power(),
+		Weight: m.TotalWeight(),
+		Score:  m.Score(),
+	}
+}
+
+// validate validates an observation of actual vs predicted values.
+func (m *Deviance) validate(actual, predicted, weight float64) error {
+	if !isFinite(actual) || !isFinite(predicted) {
+		return ErrInvalidNumeric
+	}
+	if !m.isValidDomain(actual, predicted) {
+		return ErrOutOfDomain
+	}
+	return validateWeight(weight)
+}
+
+func (m *Deviance) isValidDomain(actual, predicted float64) bool {
+	switch p := m.power; {
+	case p < 0:
+		return predicted > 0
+	case p == 0:
+		return true
+	case p < 2:
+		return actual >= 0 && predicted > 0
+	default:
+		return actual > 0 && predicted > 0
+	}
+}
+
+func (m *Deviance) unitDeviance(actual, predicted float64) float64 {
+	switch p := m.power; p {
+	case 0:
+		delta := actual - predicted
+		return delta * delta
+	case 1:
+		dev := predicted - actual
+		if actual > 0 {
+			dev += actual * math.Log(actual/predicted)
+		}
+		return 2 * dev
+	case 2:
+		return 2 * (math.Log(predicted/actual) + actual/predicted - 1)
+	default:
+		dev := actual*math.Pow(predicted, 1-p)/(p-1) + math.Pow(predicted, 2-p)/(2-p)
+		if actual > 0 {
+			dev += math.Pow(actual, 2-p) / ((1 - p) * (2 - p))
+		}
+		return 2 * dev
+	}
+}
+
+// --------------------------------------------------------------------
+
+// HuberLoss is a regression loss that is quadratic for small residuals and linear for large
+// ones, making it less sensitive to outliers than the mean squared error.
+// https://en.wikipedia.org/wiki/Huber_loss
+type HuberLoss struct {
+	rejector
+
+	delta float64
+
+	weight  float64 // total weight observed
+	lossSum float64 // weighted sum of losses
+
+	mu sync.RWMutex
+}
+
+// HuberLossSnapshot is a point-in-time snapshot of a HuberLoss metric.
+type HuberLossSnapshot struct {
+	Weight   float64    // total weight observed
+	Score    float64    // mean Huber loss
+	Rejected Rejections // observations rejected by validation
+}
+
+// NewHuberLoss inits a new metric with delta, the residual threshold at which the loss
+// changes from quadratic to linear. Default: 1.0.
+func NewHuberLoss(delta float64) *HuberLoss {
+	if delta <= 0 {
+		delta = 1.0
+	}
+	return &HuberLoss{delta: delta}
+}
+
+// Reset resets state.
+func (m *HuberLoss) Reset() {
+	m.mu.Lock()
+	m.reset()
+	m.mu.Unlock()
+	m.resetRejected()
+}
+
+// Observe records an observation of the actual vs the predicted value.
+func (m *HuberLoss) Observe(actual, predicted float64) {
+	m.ObserveWeight(actual, predicted, 1.0)
+}
+
+// ObserveWeight records an observation of the actual vs the predicted value with a given weight.
+func (m *HuberLoss) ObserveWeight(actual, predicted, weight float64) {
+	_ = m.ObserveWeightStrict(actual, predicted, weight)
+}
+
+// ObserveWeightStrict is like ObserveWeight, but returns an error if the observation was
+// rejected by validation, see Rejected.
+func (m *HuberLoss) ObserveWeightStrict(actual, predicted, weight float64) error {
+	if err := m.validate(actual, predicted, weight); err != nil {
+		return m.reject(err)
+	}
+
+	m.mu.Lock()
+	m.observe(actual, predicted, weight)
+	m.mu.Unlock()
+	return nil
+}
+
+// ObserveBatch records a batch of observations of actual vs predicted values.
+// Weights are optional, if given, they must match the number of observations.
+// Returns ErrLengthMismatch if the lengths of the inputs differ and a *BatchError
+// if observations were rejected.
+func (m *HuberLoss) ObserveBatch(actual, predicted []float64, weights []float64) error {
+	if err := validateBatch(len(actual), weights, len(predicted)); err != nil {
+		return m.rejectBatch(len(actual), err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var berr BatchError
+	for i := range actual {
+		weight := batchWeight(weights, i)
+		if err := m.validate(actual[i], predicted[i], weight); err != nil {
+			berr.add(i, m.reject(err))
+			continue
+		}
+		m.observe(actual[i], predicted[i], weight)
+	}
+	return berr.errOrNil()
+}
+
+// TotalWeight returns the total weight observed.
+func (m *HuberLoss) TotalWeight() float64 {
+	m.mu.RLock()
+	weight := m.weight
+	m.mu.RUnlock()
+	return weight
+}
+
+// Score calculates the mean Huber loss.
+func (m *HuberLoss) Score() float64 {
+	m.mu.RLock()
+	weight := m.weight
+	lossSum := m.lossSum
+	m.mu.RUnlock()
+
+	if weight > 0 {
+		return lossSum / weight
+	}
+	return 0.0
+}
+
+// Snapshot returns a consistent point-in-time snapshot of all values.
+func (m *HuberLoss) Snapshot() HuberLossSnapshot {
+	m.mu.RLock()
+	c := m.clone()
+	m.mu.RUnlock()
+
+	snap := c.snapshot()
+	snap.Rejected = m.Rejected()
+	return snap
+}
+
+// SnapshotAndReset atomically returns a snapshot of all values and resets state.
+func (m *HuberLoss) SnapshotAndReset() HuberLossSnapshot {
+	m.mu.Lock()
+	c := m.clone()
+	m.reset()
+	m.mu.Unlock()
+
+	snap := c.snapshot()
+	snap.Rejected = m.harvestRejected()
+	return snap
+}
+
+// validate validates an observation of actual vs predicted values. Infinite
+// residuals would turn the mean loss into NaN or Inf.
+func (m *HuberLoss) validate(actual, predicted, weight float64) error {
+	if !isFinite(actual) || !isFinite(predicted) {
+		return ErrInvalidNumeric
+	}
+	return validateWeight(weight)
+}
+
+func (m *HuberLoss) observe(actual, predicted, weight float64) {
+	residual := math.Abs(actual - predicted)
+	loss := 0.5 * residual * residual
+	if residual > m.delta {
+		loss = m.delta * (residual - 0.5*m.delta)
+	}
+
+	m.weight += weight
+	m.lossSum += loss * weight
+}
+
+func (m *HuberLoss) clone() *HuberLoss {
+	return &HuberLoss{delta: m.delta, weight: m.weight, lossSum: m.lossSum}
+}
+
+func (m *HuberLoss) reset() {
+	m.weight = 0
+	m.lossSum = 0
+}
+
+func (m *HuberLoss) snapshot() HuberLossSnapshot {
+	return HuberLossSnapshot{
+		Weight: m.TotalWeight(),
+		Score:  m.Score(),
+	}
+}
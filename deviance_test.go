@@ -0,0 +1,169 @@
+package mlmetrics_test
+
+import (
+	"math"
+
+	. "github.com/bsm/ginkgo"
+	. "github.com/bsm/gomega"
+	"github.com/bsm/mlmetrics"
+)
+
+var _ = Describe("Deviance", func() {
+	observe := func(m *mlmetrics.Deviance, actual, predicted []float64) {
+		for i := range actual {
+			m.Observe(actual[i], predicted[i])
+		}
+	}
+
+	It("should calculate Poisson deviance", func() {
+		subject := mlmetrics.NewPoissonDeviance()
+		observe(subject, []float64{2, 0, 1, 4}, []float64{0.5, 0.5, 2, 2})
+		Expect(subject.Power()).To(Equal(1.0))
+		Expect(subject.TotalWeight()).To(Equal(4.0))
+		Expect(subject.Score()).To(BeNumerically("~", 1.426, 0.001))
+	})
+
+	It("should calculate Gamma deviance", func() {
+		subject := mlmetrics.NewGammaDeviance()
+		observe(subject, []float64{2, 0.5, 1, 4}, []float64{0.5, 0.5, 2, 2})
+		Expect(subject.Score()).To(BeNumerically("~", 1.057, 0.001))
+	})
+
+	It("should calculate Tweedie deviance", func() {
+		subject := mlmetrics.NewTweedieDeviance(0)
+		observe(subject, []float64{2, -1, 1, 4}, []float64{0.5, 0.5, 2, 2})
+		Expect(subject.Score()).To(BeNumerically("~", 2.375, 0.001))
+
+		subject = mlmetrics.NewTweedieDeviance(1.5)
+		observe(subject, []float64{2, 0, 1, 4}, []float64{0.5, 0.5, 2, 2})
+		Expect(subject.Score()).To(BeNumerically("~", 1.778, 0.001))
+
+		subject = mlmetrics.NewTweedieDeviance(3)
+		observe(subject, []float64{2, 0.5, 1, 4}, []float64{0.5, 0.5, 2, 2})
+		Expect(subject.Score()).To(BeNumerically("~", 1.250, 0.001))
+
+		subject = mlmetrics.NewTweedieDeviance(-1)
+		observe(subject, []float64{2, -1, 1, 4}, []float64{0.5, 0.5, 2, 2})
+		Expect(subject.Score()).To(BeNumerically("~", 3.729, 0.001))
+	})
+
+	It("should fall back to the default power", func() {
+		Expect(mlmetrics.NewTweedieDeviance(0.5).Power()).To(Equal(0.0))
+		Expect(mlmetrics.NewTweedieDeviance(math.NaN()).Power()).To(Equal(0.0))
+		Expect(mlmetrics.NewTweedieDeviance(1).Power()).To(Equal(1.0))
+	})
+
+	It("should count observations outside the domain", func() {
+		subject := mlmetrics.NewPoissonDeviance()
+		observe(subject, []float64{2, 0, 1, 4}, []float64{0.5, 0.5, 2, 2})
+		subject.Observe(-1, 2)
+		subject.Observe(1, 0)
+		subject.ObserveWeight(1, 1, 0)
+		Expect(subject.TotalWeight()).To(Equal(4.0))
+		Expect(subject.OutOfDomain()).To(Equal(2))
+		Expect(subject.Score()).To(BeNumerically("~", 1.426, 0.001))
+
+		subject = mlmetrics.NewGammaDeviance()
+		subject.Observe(0, 1)
+		Expect(subject.OutOfDomain()).To(Equal(1))
+	})
+
+	It("should reject invalid observations", func() {
+		subject := mlmetrics.NewPoissonDeviance()
+		subject.Observe(math.NaN(), 1)
+		subject.Observe(1, math.Inf(1))
+		subject.Observe(-1, 2)
+		Expect(subject.TotalWeight()).To(BeZero())
+		Expect(subject.OutOfDomain()).To(Equal(1))
+		Expect(subject.Rejected()).To(Equal(mlmetrics.Rejections{InvalidNumeric: 2, OutOfDomain: 1}))
+
+		Expect(subject.ObserveWeightStrict(1, 0, 1)).To(MatchError(mlmetrics.ErrOutOfDomain))
+		Expect(subject.ObserveWeightStrict(math.NaN(), 1, 1)).To(MatchError(mlmetrics.ErrInvalidNumeric))
+		Expect(subject.ObserveBatch([]float64{1, -1}, []float64{1, 1}, nil)).To(MatchError(mlmetrics.ErrOutOfDomain))
+		Expect(subject.TotalWeight()).To(Equal(1.0))
+		Expect(subject.OutOfDomain()).To(Equal(3))
+	})
+
+	It("should handle blanks", func() {
+		subject := mlmetrics.NewPoissonDeviance()
+		subject.Observe(-1, 2)
+		subject.Reset()
+		Expect(subject.TotalWeight()).To(Equal(0.0))
+		Expect(subject.OutOfDomain()).To(Equal(0))
+		Expect(subject.Score()).To(Equal(0.0))
+	})
+
+	It("should reset out-of-domain counts per interval", func() {
+		subject := mlmetrics.NewPoissonDeviance()
+		subject.Observe(-1, 2)
+		subject.Observe(1, 2)
+
+		snap := subject.SnapshotAndReset()
+		Expect(snap.OutOfDomain).To(Equal(1))
+		Expect(snap.Rejected).To(Equal(mlmetrics.Rejections{OutOfDomain: 1}))
+		Expect(subject.OutOfDomain()).To(Equal(0))
+		Expect(subject.Rejected()).To(BeZero())
+	})
+
+	It("should observe batches", func() {
+		expected := mlmetrics.NewPoissonDeviance()
+		observe(expected, []float64{2, 0, 1, -4}, []float64{0.5, 0.5, 2, 2})
+
+		subject := mlmetrics.NewPoissonDeviance()
+		Expect(subject.ObserveBatch([]float64{2, 0, 1, -4}, []float64{0.5, 0.5, 2, 2}, nil)).To(MatchError(mlmetrics.ErrOutOfDomain))
+		Expect(subject.Snapshot()).To(Equal(expected.Snapshot()))
+		Expect(subject.ObserveBatch([]float64{2}, nil, nil)).To(MatchError(mlmetrics.ErrLengthMismatch))
+	})
+})
+
+var _ = Describe("HuberLoss", func() {
+	var subject *mlmetrics.HuberLoss
+
+	BeforeEach(func() {
+		subject = mlmetrics.NewHuberLoss(1.0)
+		subject.Observe(3, 2.5)
+		subject.Observe(-0.5, 0)
+		subject.Observe(2, 2)
+		subject.Observe(7, 8)
+		subject.Observe(1, 4)
+	})
+
+	It("should calculate score", func() {
+		Expect(subject.TotalWeight()).To(Equal(5.0))
+		Expect(subject.Score()).To(BeNumerically("~", 0.65, 0.001))
+
+		subject.ObserveWeight(1, 4, 5.0)
+		Expect(subject.Score()).To(BeNumerically("~", 1.575, 0.001))
+	})
+
+	It("should handle blanks", func() {
+		subject.Reset()
+		Expect(subject.TotalWeight()).To(Equal(0.0))
+		Expect(subject.Score()).To(Equal(0.0))
+	})
+
+	It("should observe batches", func() {
+		expected := mlmetrics.NewHuberLoss(1)
+		expected.Observe(1, 3)
+		expected.ObserveWeight(2, 2.5, 2)
+
+		subject = mlmetrics.NewHuberLoss(1)
+		Expect(subject.ObserveBatch([]float64{1, 2}, []float64{3, 2.5}, []float64{1, 2})).To(Succeed())
+		Expect(subject.Snapshot()).To(Equal(expected.Snapshot()))
+		Expect(subject.ObserveBatch([]float64{2}, nil, nil)).To(MatchError(mlmetrics.ErrLengthMismatch))
+	})
+
+	It("should reject non-finite values", func() {
+		Expect(subject.ObserveWeightStrict(math.Inf(1), 2, 1)).To(MatchError(mlmetrics.ErrInvalidNumeric))
+		Expect(subject.ObserveWeightStrict(1, math.Inf(-1), 1)).To(MatchError(mlmetrics.ErrInvalidNumeric))
+		Expect(subject.ObserveWeightStrict(math.NaN(), 2, 1)).To(MatchError(mlmetrics.ErrInvalidNumeric))
+
+		err := subject.ObserveBatch([]float64{1, math.Inf(1)}, []float64{2, 2}, nil)
+		Expect(err).To(MatchError(mlmetrics.ErrInvalidNumeric))
+		Expect(err.(*mlmetrics.BatchError).Index).To(Equal(1))
+
+		Expect(subject.TotalWeight()).To(Equal(6.0))
+		Expect(subject.Score()).To(BeNumerically("~", 0.625, 0.001))
+		Expect(subject.Rejected().InvalidNumeric).To(Equal(int64(4)))
+	})
+})
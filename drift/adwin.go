@@ -0,0 +1,217 @@
+package drift
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	adwinMaxBuckets = 5  // maximum number of buckets per row
+	adwinClock      = 32 // check for changes every n observations
+	adwinMinWindow  = 5  // minimum length of each sub-window
+)
+
+// ADWIN implements the adaptive windowing algorithm by Bifet and Gavaldà (2007). It
+// keeps a window of recent error signals, compressed in an exponential histogram, and
+// drops older observations whenever two sub-windows exhibit significantly different
+// means. It accepts arbitrary error signals, e.g. 0/1 misclassification indicators or
+// regression errors scaled to [0, 1]. ADWIN does not emit warnings.
+// https://doi.org/10.1137/1.9781611972771.42
+type ADWIN struct {
+	delta float64
+
+	rows     [][]adwinBucket // rows[i] holds buckets of 2^i observations, oldest first
+	width    float64         // number of observations in the window
+	total    float64         // sum of observations in the window
+	variance float64         // sum of squared deviations in the window
+	ticks    int
+
+	status Status
+	mu     sync.Mutex
+}
+
+type adwinBucket struct {
+	n        float64 // number of observations
+	total    float64 // sum of observations
+	variance float64 // sum of squared deviations
+}
+
+func (b adwinBucket) mean() float64 { return b.total / b.n }
+
+// NewADWIN inits a new detector with a confidence level delta, lower values result
+// in fewer false positives but slower detection. Default: 0.002.
+func NewADWIN(delta float64) *ADWIN {
+	if delta <= 0 || delta >= 1 {
+		delta = 0.002
+	}
+	return &ADWIN{delta: delta}
+}
+
+// Reset resets state.
+func (m *ADWIN) Reset() {
+	m.mu.Lock()
+	m.reset()
+	m.status = Status{}
+	m.mu.Unlock()
+}
+
+// Observe implements Detector.
+func (m *ADWIN) Observe(value float64) State {
+	return m.ObserveAt(value, time.Now())
+}
+
+// ObserveAt implements Detector.
+func (m *ADWIN) ObserveAt(value float64, t time.Time) State {
+	if !isValidSignal(value) {
+		return m.Status().State
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.insert(value)
+
+	state := StateStable
+	if m.ticks++; m.ticks%adwinClock == 0 && m.shrink() {
+		state = StateDrift
+	}
+
+	// unlike DDM and EDDM, ADWIN retains the recent window after a drift, only
+	// reset the observation count in the status
+	m.status.transition(state, t)
+	if state == StateDrift {
+		m.status.Observations = int(m.width)
+	}
+	return state
+}
+
+// Status implements Detector.
+func (m *ADWIN) Status() Status {
+	m.mu.Lock()
+	status := m.status
+	m.mu.Unlock()
+	return status
+}
+
+// Width returns the current window size.
+func (m *ADWIN) Width() int {
+	m.mu.Lock()
+	width := m.width
+	m.mu.Unlock()
+	return int(width)
+}
+
+// Mean returns the mean of the current window.
+func (m *ADWIN) Mean() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.width > 0 {
+		return m.total / m.width
+	}
+	return 0.0
+}
+
+func (m *ADWIN) reset() {
+	m.rows = nil
+	m.width = 0
+	m.total = 0
+	m.variance = 0
+	m.ticks = 0
+}
+
+func (m *ADWIN) insert(value float64) {
+	if m.width > 0 {
+		mean := m.total / m.width
+		m.variance += m.width * (value - mean) * (value - mean) / (m.width + 1)
+	}
+	m.width++
+	m.total += value
+
+	if len(m.rows) == 0 {
+		m.rows = append(m.rows, nil)
+	}
+	m.rows[0] = append(m.rows[0], adwinBucket{n: 1, total: value})
+
+	// compress
+	for i := 0; i < len(m.rows); i++ {
+		if len(m.rows[i]) <= adwinMaxBuckets {
+			break
+		}
+
+		b1, b2 := m.rows[i][0], m.rows[i][1]
+		merged := adwinBucket{
+			n:        b1.n + b2.n,
+			total:    b1.total + b2.total,
+			variance: b1.variance + b2.variance + b1.n*b2.n*math.Pow(b1.mean()-b2.mean(), 2)/(b1.n+b2.n),
+		}
+		m.rows[i] = append(m.rows[i][:0], m.rows[i][2:]...)
+		if i+1 == len(m.rows) {
+			m.rows = append(m.rows, nil)
+		}
+		m.rows[i+1] = append(m.rows[i+1], merged)
+	}
+}
+
+// shrink drops the oldest buckets while the window contains two sub-windows with
+// significantly different means. Returns true if the window was shrunk.
+func (m *ADWIN) shrink() (shrunk bool) {
+	for m.width > 2*adwinMinWindow && m.hasCut() {
+		m.dropOldest()
+		shrunk = true
+	}
+	return
+}
+
+// hasCut checks all splits between buckets, from oldest to newest.
+func (m *ADWIN) hasCut() bool {
+	dd := math.Log(2 * math.Log(m.width) / m.delta)
+	v := m.variance / m.width
+
+	var n0, total0 float64
+	for i := len(m.rows) - 1; i >= 0; i-- {
+		for _, b := range m.rows[i] {
+			n0 += b.n
+			total0 += b.total
+
+			n1 := m.width - n0
+			if n1 < adwinMinWindow {
+				return false
+			}
+			if n0 < adwinMinWindow {
+				continue
+			}
+
+			diff := math.Abs(total0/n0 - (m.total-total0)/n1)
+			hm := 1 / (1/n0 + 1/n1)
+			eps := math.Sqrt(2/hm*v*dd) + 2/(3*hm)*dd
+			if diff > eps {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m *ADWIN) dropOldest() {
+	last := len(m.rows) - 1
+	b := m.rows[last][0]
+	m.rows[last] = m.rows[last][1:]
+	if len(m.rows[last]) == 0 {
+		m.rows = m.rows[:last]
+	}
+
+	rest := m.width - b.n
+	if rest > 0 {
+		restMean := (m.total - b.total) / rest
+		m.variance -= b.variance + b.n*rest*math.Pow(b.mean()-restMean, 2)/m.width
+	} else {
+		m.variance = 0
+	}
+	if m.variance < 0 {
+		m.variance = 0
+	}
+	m.width = rest
+	m.total -= b.total
+}
@@ -0,0 +1,210 @@
+package drift
+
+import (
+	"sort"
+	"sync"
+)
+
+// Categorical compares the distribution of a categorical feature in a live stream
+// against a reference distribution. Categories which are not part of the reference
+// are collected in a separate bin.
+type Categorical struct {
+	index   map[string]int // category to bin index
+	refHist []float64      // reference bin weights, last bin is for unknown categories
+
+	liveHist []float64
+
+	mu sync.RWMutex
+}
+
+// CategoricalSnapshot is a point-in-time snapshot of a Categorical detector.
+type CategoricalSnapshot struct {
+	Weight        float64 // total weight of live observations
+	UnknownWeight float64 // weight of live observations of unknown categories
+	PSI           float64 // population stability index
+	KL            float64 // Kullback-Leibler divergence
+	JS            float64 // Jensen-Shannon divergence
+}
+
+// NewCategorical inits a new detector with a reference distribution of weights
+// (e.g. counts) by category.
+func NewCategorical(reference map[string]float64) *Categorical {
+	names := make([]string, 0, len(reference))
+	for name, w := range reference {
+		if isValidWeight(w) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	index := make(map[string]int, len(names))
+	refHist := make([]float64, len(names)+1)
+	for i, name := range names {
+		index[name] = i
+		refHist[i] = reference[name]
+	}
+
+	return &Categorical{
+		index:    index,
+		refHist:  refHist,
+		liveHist: make([]float64, len(refHist)),
+	}
+}
+
+// Reset resets the live state.
+func (m *Categorical) Reset() {
+	m.mu.Lock()
+	m.reset()
+	m.mu.Unlock()
+}
+
+// Observe records a live observation.
+func (m *Categorical) Observe(category string) {
+	m.ObserveWeight(category, 1.0)
+}
+
+// ObserveWeight records a live observation with a given weight.
+func (m *Categorical) ObserveWeight(category string, weight float64) {
+	if !isValidWeight(weight) {
+		return
+	}
+
+	pos := m.position(category)
+
+	m.mu.Lock()
+	m.liveHist[pos] += weight
+	m.mu.Unlock()
+}
+
+// ObserveBatch records a batch of live observations.
+// Weights are optional, if given, they must match the number of observations.
+// Returns ErrLengthMismatch otherwise.
+func (m *Categorical) ObserveBatch(categories []string, weights []float64) error {
+	if weights != nil && len(weights) != len(categories) {
+		return ErrLengthMismatch
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, category := range categories {
+		if weight := batchWeight(weights, i); isValidWeight(weight) {
+			m.liveHist[m.position(category)] += weight
+		}
+	}
+	return nil
+}
+
+// TotalWeight returns the total weight of live observations.
+func (m *Categorical) TotalWeight() float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var sum float64
+	for _, w := range m.liveHist {
+		sum += w
+	}
+	return sum
+}
+
+// UnknownWeight returns the weight of live observations with categories that
+// are not part of the reference distribution.
+func (m *Categorical) UnknownWeight() float64 {
+	m.mu.RLock()
+	weight := m.liveHist[len(m.liveHist)-1]
+	m.mu.RUnlock()
+	return weight
+}
+
+// PSI calculates the population stability index.
+func (m *Categorical) PSI() float64 {
+	live, ref, ok := m.proportions()
+	if !ok {
+		return 0.0
+	}
+	return psi(live, ref)
+}
+
+// KL calculates the Kullback-Leibler divergence of the live from the reference
+// distribution in nats.
+func (m *Categorical) KL() float64 {
+	live, ref, ok := m.proportions()
+	if !ok {
+		return 0.0
+	}
+	return kl(live, ref)
+}
+
+// JS calculates the Jensen-Shannon divergence between the live and the reference
+// distribution in nats, between 0 and ln(2).
+func (m *Categorical) JS() float64 {
+	live, ref, ok := m.proportions()
+	if !ok {
+		return 0.0
+	}
+	return js(live, ref)
+}
+
+// Snapshot returns a consistent point-in-time snapshot of all values.
+func (m *Categorical) Snapshot() CategoricalSnapshot {
+	m.mu.RLock()
+	c := m.clone()
+	m.mu.RUnlock()
+
+	return c.snapshot()
+}
+
+// SnapshotAndReset atomically returns a snapshot of all values and resets state.
+func (m *Categorical) SnapshotAndReset() CategoricalSnapshot {
+	m.mu.Lock()
+	c := m.clone()
+	m.reset()
+	m.mu.Unlock()
+
+	return c.snapshot()
+}
+
+// position returns the histogram position of a category, unknown categories are
+// collected in the last position.
+func (m *Categorical) position(category string) int {
+	if pos, ok := m.index[category]; ok {
+		return pos
+	}
+	return len(m.refHist) - 1
+}
+
+func (m *Categorical) clone() *Categorical {
+	return &Categorical{
+		index:    m.index,
+		refHist:  m.refHist,
+		liveHist: append([]float64(nil), m.liveHist...),
+	}
+}
+
+func (m *Categorical) reset() {
+	m.liveHist = make([]float64, len(m.refHist))
+}
+
+func (m *Categorical) snapshot() CategoricalSnapshot {
+	return CategoricalSnapshot{
+		Weight:        m.TotalWeight(),
+		UnknownWeight: m.UnknownWeight(),
+		PSI:           m.PSI(),
+		KL:            m.KL(),
+		JS:            m.JS(),
+	}
+}
+
+func (m *Categorical) proportions() (live, ref []float64, ok bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var sum float64
+	for _, w := range m.liveHist {
+		sum += w
+	}
+	if sum == 0 || len(m.refHist) < 2 {
+		return nil, nil, false
+	}
+	return proportions(m.liveHist), proportions(m.refHist), true
+}
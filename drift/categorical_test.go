@@ -0,0 +1,89 @@
+package drift_test
+
+import (
+	. "github.com/bsm/ginkgo"
+	. "github.com/bsm/gomega"
+	"github.com/bsm/mlmetrics/drift"
+)
+
+var _ = Describe("Categorical", func() {
+	var subject *drift.Categorical
+
+	BeforeEach(func() {
+		subject = drift.NewCategorical(map[string]float64{"a": 50, "b": 50, "c": 0})
+	})
+
+	It("should detect no drift", func() {
+		subject.ObserveWeight("a", 10)
+		subject.ObserveWeight("b", 10)
+		Expect(subject.TotalWeight()).To(Equal(20.0))
+		Expect(subject.PSI()).To(BeNumerically("~", 0.0, 0.001))
+		Expect(subject.KL()).To(BeNumerically("~", 0.0, 0.001))
+		Expect(subject.JS()).To(BeNumerically("~", 0.0, 0.001))
+	})
+
+	It("should detect drift", func() {
+		subject.ObserveWeight("a", 25)
+		subject.ObserveWeight("b", 75)
+		Expect(subject.PSI()).To(BeNumerically("~", 0.275, 0.001))
+		Expect(subject.KL()).To(BeNumerically("~", 0.131, 0.001))
+		Expect(subject.JS()).To(BeNumerically("~", 0.034, 0.001))
+	})
+
+	It("should collect unknown categories", func() {
+		subject.Observe("a")
+		subject.Observe("c")
+		subject.Observe("d")
+		Expect(subject.TotalWeight()).To(Equal(3.0))
+		Expect(subject.UnknownWeight()).To(Equal(2.0))
+		Expect(subject.PSI()).To(BeNumerically(">", 3.0))
+		Expect(subject.JS()).To(BeNumerically("<=", 0.694))
+	})
+
+	It("should handle blanks", func() {
+		Expect(subject.PSI()).To(Equal(0.0))
+		Expect(subject.KL()).To(Equal(0.0))
+		Expect(subject.JS()).To(Equal(0.0))
+
+		subject.Observe("a")
+		subject.Reset()
+		Expect(subject.TotalWeight()).To(Equal(0.0))
+		Expect(subject.PSI()).To(Equal(0.0))
+
+		empty := drift.NewCategorical(nil)
+		empty.Observe("a")
+		Expect(empty.PSI()).To(Equal(0.0))
+	})
+
+	It("should snapshot", func() {
+		subject.ObserveWeight("a", 25)
+		subject.ObserveWeight("b", 75)
+		subject.Observe("d")
+		Expect(subject.Snapshot()).To(Equal(drift.CategoricalSnapshot{
+			Weight:        101,
+			UnknownWeight: 1,
+			PSI:           subject.PSI(),
+			KL:            subject.KL(),
+			JS:            subject.JS(),
+		}))
+	})
+
+	It("should snapshot and reset", func() {
+		subject.ObserveWeight("a", 25)
+		subject.Observe("d")
+
+		snap := subject.Snapshot()
+		Expect(subject.SnapshotAndReset()).To(Equal(snap))
+		Expect(subject.Snapshot().Weight).To(BeZero())
+	})
+
+	It("should observe batches", func() {
+		expected := drift.NewCategorical(map[string]float64{"a": 50, "b": 50, "c": 0})
+		expected.Observe("a")
+		expected.ObserveWeight("d", 2)
+
+		Expect(subject.ObserveBatch([]string{"a", "d"}, []float64{1, 2})).To(Succeed())
+		Expect(subject.Snapshot()).To(Equal(expected.Snapshot()))
+		Expect(subject.ObserveBatch([]string{"a"}, []float64{})).To(MatchError(drift.ErrLengthMismatch))
+	})
+})
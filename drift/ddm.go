@@ -0,0 +1,198 @@
+package drift
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// ddmMinObservations is the minimum number of observations, and additionally the
+// minimum number of errors for EDDM, before drift detection starts.
+const ddmMinObservations = 30
+
+// DDM implements the drift detection method by Gama et al. (2004). It monitors the
+// error rate of a classifier and expects error signals of either 0 (correct) or 1
+// (incorrect).
+// https://doi.org/10.1007/978-3-540-28645-5_29
+type DDM struct {
+	n    float64 // number of observations
+	p    float64 // error rate
+	pMin float64 // error rate at minimum
+	sMin float64 // standard deviation at minimum
+
+	status Status
+	mu     sync.Mutex
+}
+
+// NewDDM inits a new detector.
+func NewDDM() *DDM {
+	m := new(DDM)
+	m.reset()
+	return m
+}
+
+// Reset resets state.
+func (m *DDM) Reset() {
+	m.mu.Lock()
+	m.reset()
+	m.status = Status{}
+	m.mu.Unlock()
+}
+
+// Observe implements Detector.
+func (m *DDM) Observe(value float64) State {
+	return m.ObserveAt(value, time.Now())
+}
+
+// ObserveAt implements Detector.
+func (m *DDM) ObserveAt(value float64, t time.Time) State {
+	if !isValidSignal(value) {
+		return m.Status().State
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.n++
+	if value > 0 {
+		value = 1
+	} else {
+		value = 0
+	}
+	m.p += (value - m.p) / m.n
+	s := math.Sqrt(m.p * (1 - m.p) / m.n)
+
+	state := StateStable
+	if m.n >= ddmMinObservations {
+		if m.p+s <= m.pMin+m.sMin {
+			m.pMin, m.sMin = m.p, s
+		}
+
+		if m.p+s >= m.pMin+3*m.sMin {
+			state = StateDrift
+			m.reset()
+		} else if m.p+s >= m.pMin+2*m.sMin {
+			state = StateWarning
+		}
+	}
+
+	m.status.transition(state, t)
+	return state
+}
+
+// Status implements Detector.
+func (m *DDM) Status() Status {
+	m.mu.Lock()
+	status := m.status
+	m.mu.Unlock()
+	return status
+}
+
+func (m *DDM) reset() {
+	m.n = 0
+	m.p = 0
+	m.pMin = math.Inf(1)
+	m.sMin = math.Inf(1)
+}
+
+// --------------------------------------------------------------------
+
+// EDDM implements the early drift detection method by Baena-García et al. (2006),
+// which monitors the distance between consecutive errors and is better suited to
+// detect slow, gradual changes than DDM. It expects error signals of either 0
+// (correct) or 1 (incorrect).
+type EDDM struct {
+	n         float64 // number of observations
+	errors    float64 // number of distances between errors
+	lastError float64 // observation number of the last error
+	mean      float64 // mean distance between errors
+	sumSq     float64 // sum of squared deviations of distances
+	maxScore  float64 // maximum of mean + 2 * std
+
+	status Status
+	mu     sync.Mutex
+}
+
+const (
+	eddmWarning = 0.95
+	eddmDrift   = 0.90
+)
+
+// NewEDDM inits a new detector.
+func NewEDDM() *EDDM {
+	return new(EDDM)
+}
+
+// Reset resets state.
+func (m *EDDM) Reset() {
+	m.mu.Lock()
+	m.reset()
+	m.status = Status{}
+	m.mu.Unlock()
+}
+
+// Observe implements Detector.
+func (m *EDDM) Observe(value float64) State {
+	return m.ObserveAt(value, time.Now())
+}
+
+// ObserveAt implements Detector.
+func (m *EDDM) ObserveAt(value float64, t time.Time) State {
+	if !isValidSignal(value) {
+		return m.Status().State
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.n++
+
+	state := StateStable
+	if value > 0 && m.lastError == 0 {
+		m.lastError = m.n
+	} else if value > 0 {
+		distance := m.n - m.lastError
+		m.lastError = m.n
+		m.errors++
+
+		delta := distance - m.mean
+		m.mean += delta / m.errors
+		m.sumSq += delta * (distance - m.mean)
+
+		score := m.mean + 2*math.Sqrt(m.sumSq/m.errors)
+		if m.n < ddmMinObservations {
+			// too early
+		} else if score > m.maxScore {
+			m.maxScore = score
+		} else if m.errors >= ddmMinObservations {
+			if ratio := score / m.maxScore; ratio < eddmDrift {
+				state = StateDrift
+				m.reset()
+			} else if ratio < eddmWarning {
+				state = StateWarning
+			}
+		}
+	} else if m.status.State == StateWarning {
+		state = StateWarning
+	}
+
+	m.status.transition(state, t)
+	return state
+}
+
+// Status implements Detector.
+func (m *EDDM) Status() Status {
+	m.mu.Lock()
+	status := m.status
+	m.mu.Unlock()
+	return status
+}
+
+func (m *EDDM) reset() {
+	m.n = 0
+	m.errors = 0
+	m.lastError = 0
+	m.mean = 0
+	m.sumSq = 0
+	m.maxScore = 0
+}
@@ -0,0 +1,76 @@
+package drift
+
+import (
+	"math"
+	"time"
+)
+
+// State is the state of a concept drift detector.
+type State int
+
+// Detector states.
+const (
+	StateStable State = iota
+	StateWarning
+	StateDrift
+)
+
+// String returns the state name.
+func (s State) String() string {
+	switch s {
+	case StateStable:
+		return "stable"
+	case StateWarning:
+		return "warning"
+	case StateDrift:
+		return "drift"
+	}
+	return "unknown"
+}
+
+// Status is the status of a concept drift detector.
+type Status struct {
+	State        State     // the current state
+	WarningSince time.Time // start of the current warning period, zero unless in warning state
+	LastDrift    time.Time // time of the most recently detected drift, zero if none
+	Drifts       int       // number of drifts detected
+	Observations int       // number of observations since the last drift
+}
+
+// Detector is the common interface of concept drift detectors. Detectors consume
+// a stream of per-prediction error signals, e.g. 1 for an incorrect and 0 for a
+// correct prediction, or an absolute regression error, and signal when the error
+// distribution changes. After a drift is detected, detectors discard previous
+// observations and start learning the new concept.
+type Detector interface {
+	// Observe records an error signal observed now and returns the resulting state.
+	Observe(value float64) State
+	// ObserveAt records an error signal observed at time t and returns the resulting state.
+	ObserveAt(value float64, t time.Time) State
+	// Status returns the current status.
+	Status() Status
+	// Reset resets state.
+	Reset()
+}
+
+// transition updates the status with a new state observed at time t.
+func (s *Status) transition(state State, t time.Time) {
+	switch state {
+	case StateDrift:
+		s.WarningSince = time.Time{}
+		s.LastDrift = t
+		s.Drifts++
+		s.Observations = 0
+	case StateWarning:
+		if s.State != StateWarning {
+			s.WarningSince = t
+		}
+		s.Observations++
+	default:
+		s.WarningSince = time.Time{}
+		s.Observations++
+	}
+	s.State = state
+}
+
+func isValidSignal(v float64) bool { return !math.IsNaN(v) && !math.IsInf(v, 0) }
@@ -0,0 +1,125 @@
+package drift_test
+
+import (
+	"math"
+	"time"
+
+	. "github.com/bsm/ginkgo"
+	. "github.com/bsm/ginkgo/extensions/table"
+	. "github.com/bsm/gomega"
+	"github.com/bsm/mlmetrics/drift"
+)
+
+var _ = Describe("Detector", func() {
+	var _ drift.Detector = (*drift.DDM)(nil)
+	var _ drift.Detector = (*drift.EDDM)(nil)
+	var _ drift.Detector = (*drift.ADWIN)(nil)
+	var _ drift.Detector = (*drift.PageHinkley)(nil)
+
+	epoch := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// feed observes n error signals, errs out of every 10 are errors, and returns
+	// the position of the first drift, or -1.
+	feed := func(d drift.Detector, offset, n, errs int) int {
+		first := -1
+		for i := offset; i < offset+n; i++ {
+			v := 0.0
+			if i%10 < errs {
+				v = 1.0
+			}
+			if d.ObserveAt(v, epoch.Add(time.Duration(i)*time.Second)) == drift.StateDrift && first < 0 {
+				first = i
+			}
+		}
+		return first
+	}
+
+	DescribeTable("should detect drift",
+		func(d drift.Detector, maxDelay int) {
+			Expect(feed(d, 0, 1000, 1)).To(Equal(-1))
+			Expect(d.Status().State).NotTo(Equal(drift.StateDrift))
+			Expect(d.Status().Drifts).To(Equal(0))
+			Expect(d.Status().Observations).To(Equal(1000))
+
+			pos := feed(d, 1000, 1000, 6)
+			Expect(pos).To(BeNumerically(">=", 1000))
+			Expect(pos).To(BeNumerically("<", 1000+maxDelay))
+
+			status := d.Status()
+			Expect(status.Drifts).To(BeNumerically(">=", 1))
+			Expect(status.LastDrift).To(BeTemporally(">=", epoch.Add(time.Duration(pos)*time.Second)))
+
+			d.Reset()
+			Expect(d.Status()).To(Equal(drift.Status{}))
+		},
+
+		Entry("DDM", drift.NewDDM(), 200),
+		Entry("EDDM", drift.NewEDDM(), 300),
+		Entry("ADWIN", drift.NewADWIN(0), 100),
+		Entry("PageHinkley", drift.NewPageHinkley(0, 0), 200),
+	)
+
+	DescribeTable("should ignore invalid signals",
+		func(d drift.Detector) {
+			Expect(d.Observe(math.NaN())).To(Equal(drift.StateStable))
+			Expect(d.Observe(math.Inf(1))).To(Equal(drift.StateStable))
+			Expect(d.Status().Observations).To(Equal(0))
+		},
+
+		Entry("DDM", drift.NewDDM()),
+		Entry("EDDM", drift.NewEDDM()),
+		Entry("ADWIN", drift.NewADWIN(0)),
+		Entry("PageHinkley", drift.NewPageHinkley(0, 0)),
+	)
+})
+
+var _ = Describe("DDM", func() {
+	It("should warn before drift", func() {
+		subject := drift.NewDDM()
+		epoch := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		for i := 0; i < 100; i++ {
+			v := 0.0
+			if i%10 == 0 {
+				v = 1.0
+			}
+			Expect(subject.ObserveAt(v, epoch)).To(Equal(drift.StateStable))
+		}
+
+		var states []drift.State
+		for i := 0; i < 20; i++ {
+			if state := subject.ObserveAt(1, epoch.Add(time.Duration(i+1)*time.Second)); len(states) == 0 || states[len(states)-1] != state {
+				states = append(states, state)
+			}
+		}
+		Expect(states).To(Equal([]drift.State{drift.StateStable, drift.StateWarning, drift.StateDrift, drift.StateStable}))
+		Expect(subject.Status().LastDrift).To(BeTemporally(">", epoch))
+		Expect(subject.Status().WarningSince).To(BeZero())
+	})
+})
+
+var _ = Describe("ADWIN", func() {
+	It("should shrink the window on drift", func() {
+		subject := drift.NewADWIN(0.002)
+		for i := 0; i < 1000; i++ {
+			subject.Observe(0.2)
+		}
+		Expect(subject.Width()).To(Equal(1000))
+		Expect(subject.Mean()).To(BeNumerically("~", 0.2, 0.001))
+
+		for i := 0; i < 200; i++ {
+			subject.Observe(0.8)
+		}
+		Expect(subject.Status().Drifts).To(BeNumerically(">=", 1))
+		Expect(subject.Width()).To(BeNumerically("<", 400))
+		Expect(subject.Mean()).To(BeNumerically(">", 0.6))
+	})
+})
+
+var _ = Describe("State", func() {
+	It("should have names", func() {
+		Expect(drift.StateStable.String()).To(Equal("stable"))
+		Expect(drift.StateWarning.String()).To(Equal("warning"))
+		Expect(drift.StateDrift.String()).To(Equal("drift"))
+	})
+})
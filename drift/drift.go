@@ -0,0 +1,102 @@
+// Package drift detects changes between the distribution of a feature in a
+// reference data set (e.g. the training data) and its distribution in a live
+// stream of observations. It also provides concept drift detectors, which monitor
+// the error signals of a model and detect changes in its performance.
+package drift
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrLengthMismatch is returned when inputs have mismatching lengths.
+var ErrLengthMismatch = errors.New("drift: length mismatch")
+
+// epsilon is added to empty bins to avoid divisions by zero and infinite
+// divergences.
+const epsilon = 1e-4
+
+// Distributions implement the unexported helpers clone and reset, which require the
+// caller to hold the lock, and snapshot, which is only called on an unshared clone.
+
+func isValidWeight(w float64) bool  { return w > 0 }
+func isValidNumeric(v float64) bool { return !math.IsNaN(v) }
+
+// batchWeight returns the weight of observation i of a batch, weights may be nil.
+func batchWeight(weights []float64, i int) float64 {
+	if weights == nil {
+		return 1.0
+	}
+	return weights[i]
+}
+
+// proportions normalises weights into proportions, smoothing empty bins.
+func proportions(weights []float64) []float64 {
+	var sum float64
+	for _, w := range weights {
+		sum += w
+	}
+
+	props := make([]float64, len(weights))
+	if sum == 0 {
+		return props
+	}
+
+	var norm float64
+	for i, w := range weights {
+		props[i] = math.Max(w/sum, epsilon)
+		norm += props[i]
+	}
+	for i := range props {
+		props[i] /= norm
+	}
+	return props
+}
+
+// psi calculates the population stability index of actual vs expected proportions.
+func psi(actual, expected []float64) (sum float64) {
+	for i := range actual {
+		sum += (actual[i] - expected[i]) * math.Log(actual[i]/expected[i])
+	}
+	return
+}
+
+// kl calculates the Kullback-Leibler divergence of p from q.
+func kl(p, q []float64) (sum float64) {
+	for i := range p {
+		if p[i] > 0 {
+			sum += p[i] * math.Log(p[i]/q[i])
+		}
+	}
+	return
+}
+
+// js calculates the Jensen-Shannon divergence between p and q.
+func js(p, q []float64) float64 {
+	m := make([]float64, len(p))
+	for i := range p {
+		m[i] = (p[i] + q[i]) / 2
+	}
+	return (kl(p, m) + kl(q, m)) / 2
+}
+
+// ksProb calculates the asymptotic significance level of the Kolmogorov-Smirnov
+// statistic d with an effective sample size of n.
+func ksProb(d, n float64) float64 {
+	sqrtN := math.Sqrt(n)
+	lambda := (sqrtN + 0.12 + 0.11/sqrtN) * d
+	if lambda < 1e-3 {
+		return 1
+	}
+
+	var sum, sign float64 = 0, 1
+	for j := 1; j <= 100; j++ {
+		term := sign * math.Exp(-2*float64(j*j)*lambda*lambda)
+		sum += term
+		if math.Abs(term) < 1e-10 {
+			return math.Min(math.Max(2*sum, 0), 1)
+		}
+		sign = -sign
+	}
+	return 1
+}
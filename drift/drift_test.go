@@ -0,0 +1,13 @@
+package drift_test
+
+import (
+	"testing"
+
+	. "github.com/bsm/ginkgo"
+	. "github.com/bsm/gomega"
+)
+
+func TestSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "mlmetrics/drift")
+}
@@ -0,0 +1,330 @@
+package drift
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// Numeric compares the distribution of a numeric feature in a live stream against
+// a reference sample. Binned scores (PSI, KL and JS divergence) use bins of equal
+// frequency in the reference sample. Live observations are retained to calculate
+// the exact Wasserstein distance and Kolmogorov-Smirnov test, so memory grows
+// linearly with the number of live observations. Call Reset (or use SnapshotAndReset)
+// regularly to start a new comparison window and bound memory on long-running streams.
+type Numeric struct {
+	ref     []float64 // sorted reference values
+	edges   []float64 // inner bin edges
+	refHist []float64 // reference bin weights
+
+	live     []numericPoint
+	liveHist []float64
+	sorted   bool
+
+	mu sync.RWMutex
+}
+
+// NumericSnapshot is a point-in-time snapshot of a Numeric detector.
+type NumericSnapshot struct {
+	Weight      float64 // total weight of live observations
+	PSI         float64 // population stability index
+	KL          float64 // Kullback-Leibler divergence
+	JS          float64 // Jensen-Shannon divergence
+	Wasserstein float64 // first Wasserstein distance
+	KS          float64 // Kolmogorov-Smirnov statistic
+	KSPValue    float64 // Kolmogorov-Smirnov p-value
+}
+
+type numericPoint struct {
+	value  float64
+	weight float64
+}
+
+// NewNumeric inits a new detector with a reference sample and a number of bins.
+// Default: 10 bins. The reference sample is copied and retained, memory usage is
+// O(n) in the size of the reference sample plus the number of live observations.
+func NewNumeric(reference []float64, bins int) *Numeric {
+	if bins < 1 {
+		bins = 10
+	}
+
+	ref := make([]float64, 0, len(reference))
+	for _, v := range reference {
+		if isValidNumeric(v) {
+			ref = append(ref, v)
+		}
+	}
+	sort.Float64s(ref)
+
+	var edges []float64
+	if len(ref) != 0 {
+		for i := 1; i < bins; i++ {
+			edge := ref[(len(ref)-1)*i/bins]
+			if len(edges) == 0 || edge > edges[len(edges)-1] {
+				edges = append(edges, edge)
+			}
+		}
+	}
+
+	refHist := make([]float64, len(edges)+1)
+	for _, v := range ref {
+		refHist[sort.SearchFloat64s(edges, v)]++
+	}
+
+	return &Numeric{
+		ref:      ref,
+		edges:    edges,
+		refHist:  refHist,
+		liveHist: make([]float64, len(edges)+1),
+		sorted:   true,
+	}
+}
+
+// Reset resets the live state.
+func (m *Numeric) Reset() {
+	m.mu.Lock()
+	m.reset()
+	m.mu.Unlock()
+}
+
+// Observe records a live observation.
+func (m *Numeric) Observe(value float64) {
+	m.ObserveWeight(value, 1.0)
+}
+
+// ObserveWeight records a live observation with a given weight.
+func (m *Numeric) ObserveWeight(value, weight float64) {
+	if !isValidNumeric(value) || !isValidWeight(weight) {
+		return
+	}
+
+	m.mu.Lock()
+	m.observe(value, weight)
+	m.mu.Unlock()
+}
+
+// ObserveBatch records a batch of live observations.
+// Weights are optional, if given, they must match the number of observations.
+// Returns ErrLengthMismatch otherwise.
+func (m *Numeric) ObserveBatch(values, weights []float64) error {
+	if weights != nil && len(weights) != len(values) {
+		return ErrLengthMismatch
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, value := range values {
+		if weight := batchWeight(weights, i); isValidNumeric(value) && isValidWeight(weight) {
+			m.observe(value, weight)
+		}
+	}
+	return nil
+}
+
+// Bins returns the inner bin edges derived from the reference sample. The first bin
+// includes all values up to and including the first edge, the last bin all values
+// above the last edge.
+func (m *Numeric) Bins() []float64 {
+	edges := make([]float64, len(m.edges))
+	copy(edges, m.edges)
+	return edges
+}
+
+// TotalWeight returns the total weight of live observations.
+func (m *Numeric) TotalWeight() float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var sum float64
+	for _, w := range m.liveHist {
+		sum += w
+	}
+	return sum
+}
+
+// PSI calculates the population stability index. As a rule of thumb, values below
+// 0.1 indicate no significant change, values above 0.25 a significant shift.
+func (m *Numeric) PSI() float64 {
+	live, ref, ok := m.proportions()
+	if !ok {
+		return 0.0
+	}
+	return psi(live, ref)
+}
+
+// KL calculates the Kullback-Leibler divergence of the live from the reference
+// distribution in nats.
+// https://en.wikipedia.org/wiki/Kullback%E2%80%93Leibler_divergence
+func (m *Numeric) KL() float64 {
+	live, ref, ok := m.proportions()
+	if !ok {
+		return 0.0
+	}
+	return kl(live, ref)
+}
+
+// JS calculates the Jensen-Shannon divergence between the live and the reference
+// distribution in nats, between 0 and ln(2).
+// https://en.wikipedia.org/wiki/Jensen%E2%80%93Shannon_divergence
+func (m *Numeric) JS() float64 {
+	live, ref, ok := m.proportions()
+	if !ok {
+		return 0.0
+	}
+	return js(live, ref)
+}
+
+// Wasserstein calculates the first Wasserstein distance (aka earth mover's distance)
+// between the live and the reference distribution.
+// https://en.wikipedia.org/wiki/Wasserstein_metric
+func (m *Numeric) Wasserstein() float64 {
+	var dist float64
+	m.walk(func(delta, width float64) {
+		dist += math.Abs(delta) * width
+	})
+	return dist
+}
+
+// KSTest performs a two-sample Kolmogorov-Smirnov test and returns the statistic,
+// the maximum distance between the cumulative distributions, and the asymptotic
+// p-value. Weighted live observations are accounted for by their effective sample
+// size.
+// https://en.wikipedia.org/wiki/Kolmogorov%E2%80%93Smirnov_test#Two-sample_Kolmogorov%E2%80%93Smirnov_test
+func (m *Numeric) KSTest() (stat, pvalue float64) {
+	n := m.walk(func(delta, _ float64) {
+		stat = math.Max(stat, math.Abs(delta))
+	})
+	if n == 0 {
+		return 0.0, 1.0
+	}
+	return stat, ksProb(stat, n)
+}
+
+// Snapshot returns a consistent point-in-time snapshot of all values.
+func (m *Numeric) Snapshot() NumericSnapshot {
+	m.mu.RLock()
+	c := m.clone()
+	m.mu.RUnlock()
+
+	return c.snapshot()
+}
+
+// SnapshotAndReset atomically returns a snapshot of all values and resets state.
+func (m *Numeric) SnapshotAndReset() NumericSnapshot {
+	m.mu.Lock()
+	c := m.clone()
+	m.reset()
+	m.mu.Unlock()
+
+	return c.snapshot()
+}
+
+func (m *Numeric) observe(value, weight float64) {
+	m.live = append(m.live, numericPoint{value: value, weight: weight})
+	m.liveHist[sort.SearchFloat64s(m.edges, value)] += weight
+	m.sorted = false
+}
+
+func (m *Numeric) clone() *Numeric {
+	return &Numeric{
+		ref:      m.ref,
+		edges:    m.edges,
+		refHist:  m.refHist,
+		live:     append([]numericPoint(nil), m.live...),
+		liveHist: append([]float64(nil), m.liveHist...),
+		sorted:   m.sorted,
+	}
+}
+
+func (m *Numeric) reset() {
+	m.live = m.live[:0]
+	m.liveHist = make([]float64, len(m.edges)+1)
+	m.sorted = true
+}
+
+func (m *Numeric) snapshot() NumericSnapshot {
+	s := NumericSnapshot{
+		Weight:      m.TotalWeight(),
+		PSI:         m.PSI(),
+		KL:          m.KL(),
+		JS:          m.JS(),
+		Wasserstein: m.Wasserstein(),
+	}
+	s.KS, s.KSPValue = m.KSTest()
+	return s
+}
+
+func (m *Numeric) proportions() (live, ref []float64, ok bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.ref) == 0 || len(m.live) == 0 {
+		return nil, nil, false
+	}
+	return proportions(m.liveHist), proportions(m.refHist), true
+}
+
+// walk iterates over the merged reference and live samples and yields the difference
+// between both cumulative distributions and the width of each step. Returns the
+// effective sample size.
+func (m *Numeric) walk(fn func(delta, width float64)) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.ref) == 0 || len(m.live) == 0 {
+		return 0
+	}
+
+	if !m.sorted {
+		sort.Slice(m.live, func(i, j int) bool { return m.live[i].value < m.live[j].value })
+		m.sorted = true
+	}
+
+	var total, total2 float64
+	for _, p := range m.live {
+		total += p.weight
+		total2 += p.weight * p.weight
+	}
+
+	var i, j int
+	var cdfRef, cdfLive float64
+	refN := float64(len(m.ref))
+	for i < len(m.ref) || j < len(m.live) {
+		// find next value
+		x := math.Inf(1)
+		if i < len(m.ref) {
+			x = m.ref[i]
+		}
+		if j < len(m.live) && m.live[j].value < x {
+			x = m.live[j].value
+		}
+
+		// advance both distributions
+		for ; i < len(m.ref) && m.ref[i] == x; i++ {
+			cdfRef += 1 / refN
+		}
+		for ; j < len(m.live) && m.live[j].value == x; j++ {
+			cdfLive += m.live[j].weight / total
+		}
+
+		// find width to next value
+		next := math.Inf(1)
+		if i < len(m.ref) {
+			next = m.ref[i]
+		}
+		if j < len(m.live) && m.live[j].value < next {
+			next = m.live[j].value
+		}
+
+		width := 0.0
+		if !math.IsInf(next, 1) {
+			width = next - x
+		}
+		fn(cdfLive-cdfRef, width)
+	}
+
+	// effective sample size
+	liveN := total * total / total2
+	return refN * liveN / (refN + liveN)
+}
@@ -0,0 +1,135 @@
+package drift_test
+
+import (
+	"math"
+	"math/rand"
+
+	. "github.com/bsm/ginkgo"
+	. "github.com/bsm/gomega"
+	"github.com/bsm/mlmetrics/drift"
+)
+
+var _ = Describe("Numeric", func() {
+	var subject *drift.Numeric
+	var reference []float64
+
+	BeforeEach(func() {
+		reference = reference[:0]
+		for i := 0; i < 100; i++ {
+			reference = append(reference, float64(i))
+		}
+		subject = drift.NewNumeric(reference, 4)
+	})
+
+	It("should derive bins", func() {
+		Expect(subject.Bins()).To(Equal([]float64{24, 49, 74}))
+		Expect(drift.NewNumeric([]float64{1, 1, 1, 1, 2}, 4).Bins()).To(Equal([]float64{1}))
+		Expect(drift.NewNumeric(nil, 4).Bins()).To(BeEmpty())
+	})
+
+	It("should detect no drift", func() {
+		for _, v := range reference {
+			subject.Observe(v)
+		}
+		Expect(subject.TotalWeight()).To(Equal(100.0))
+		Expect(subject.PSI()).To(BeNumerically("~", 0.0, 0.001))
+		Expect(subject.KL()).To(BeNumerically("~", 0.0, 0.001))
+		Expect(subject.JS()).To(BeNumerically("~", 0.0, 0.001))
+		Expect(subject.Wasserstein()).To(BeNumerically("~", 0.0, 0.001))
+
+		stat, pvalue := subject.KSTest()
+		Expect(stat).To(BeNumerically("~", 0.0, 0.001))
+		Expect(pvalue).To(BeNumerically("~", 1.0, 0.001))
+	})
+
+	It("should detect drift", func() {
+		for _, v := range reference {
+			subject.Observe(v + 25)
+		}
+		Expect(subject.PSI()).To(BeNumerically("~", 2.128, 0.001))
+		Expect(subject.KL()).To(BeNumerically("~", 0.346, 0.001))
+		Expect(subject.JS()).To(BeNumerically("~", 0.107, 0.001))
+		Expect(subject.Wasserstein()).To(BeNumerically("~", 25.0, 0.001))
+
+		stat, pvalue := subject.KSTest()
+		Expect(stat).To(BeNumerically("~", 0.25, 0.001))
+		Expect(pvalue).To(BeNumerically("<", 0.01))
+	})
+
+	It("should calculate Wasserstein distances", func() {
+		subject = drift.NewNumeric([]float64{0, 1, 2, 3}, 2)
+		subject.ObserveWeight(1, 1)
+		subject.ObserveWeight(4, 3)
+		Expect(subject.Wasserstein()).To(BeNumerically("~", 1.75, 0.001))
+
+		stat, _ := subject.KSTest()
+		Expect(stat).To(BeNumerically("~", 0.75, 0.001))
+	})
+
+	It("should not reject samples from the same distribution", func() {
+		rnd := rand.New(rand.NewSource(1))
+		reference = reference[:0]
+		for i := 0; i < 1000; i++ {
+			reference = append(reference, rnd.NormFloat64())
+		}
+		subject = drift.NewNumeric(reference, 10)
+		for i := 0; i < 1000; i++ {
+			subject.Observe(rnd.NormFloat64())
+		}
+		Expect(subject.PSI()).To(BeNumerically("<", 0.1))
+
+		_, pvalue := subject.KSTest()
+		Expect(pvalue).To(BeNumerically(">", 0.05))
+	})
+
+	It("should handle blanks", func() {
+		Expect(subject.PSI()).To(Equal(0.0))
+		Expect(subject.Wasserstein()).To(Equal(0.0))
+		stat, pvalue := subject.KSTest()
+		Expect(stat).To(Equal(0.0))
+		Expect(pvalue).To(Equal(1.0))
+
+		subject.Observe(1)
+		subject.Reset()
+		Expect(subject.TotalWeight()).To(Equal(0.0))
+		Expect(subject.KL()).To(Equal(0.0))
+	})
+
+	It("should snapshot", func() {
+		for i := 0; i < 50; i++ {
+			subject.Observe(float64(i * 2))
+		}
+
+		snap := subject.Snapshot()
+		stat, pvalue := subject.KSTest()
+		Expect(snap).To(Equal(drift.NumericSnapshot{
+			Weight:      50,
+			PSI:         subject.PSI(),
+			KL:          subject.KL(),
+			JS:          subject.JS(),
+			Wasserstein: subject.Wasserstein(),
+			KS:          stat,
+			KSPValue:    pvalue,
+		}))
+	})
+
+	It("should snapshot and reset", func() {
+		for i := 0; i < 50; i++ {
+			subject.Observe(float64(i * 2))
+		}
+
+		snap := subject.Snapshot()
+		Expect(subject.SnapshotAndReset()).To(Equal(snap))
+		Expect(subject.Snapshot().Weight).To(BeZero())
+	})
+
+	It("should observe batches", func() {
+		expected := drift.NewNumeric(reference, 4)
+		expected.Observe(10)
+		expected.ObserveWeight(50, 2)
+
+		Expect(subject.ObserveBatch([]float64{10, 50, math.NaN()}, []float64{1, 2, 1})).To(Succeed())
+		Expect(subject.Snapshot()).To(Equal(expected.Snapshot()))
+		Expect(subject.ObserveBatch([]float64{10}, []float64{})).To(MatchError(drift.ErrLengthMismatch))
+	})
+})
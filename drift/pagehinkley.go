@@ -0,0 +1,98 @@
+package drift
+
+import (
+	"sync"
+	"time"
+)
+
+// pageHinkleyMinObservations is the minimum number of observations before drift
+// detection starts.
+const pageHinkleyMinObservations = 30
+
+// PageHinkley implements the Page-Hinkley test, a sequential analysis technique
+// that detects increases in the mean of the error signal. The detector emits a
+// warning once the test statistic exceeds half of the threshold.
+// https://doi.org/10.1093/biomet/41.1-2.100
+type PageHinkley struct {
+	delta     float64
+	threshold float64
+
+	n      float64 // number of observations
+	mean   float64 // mean of observations
+	sum    float64 // cumulative deviation
+	lowest float64 // minimum cumulative deviation
+
+	status Status
+	mu     sync.Mutex
+}
+
+// NewPageHinkley inits a new detector. The delta specifies the magnitude of changes
+// that are tolerated, the threshold the test statistic at which a drift is detected.
+// Defaults: delta 0.005, threshold 50.
+func NewPageHinkley(delta, threshold float64) *PageHinkley {
+	if delta < 0 {
+		delta = 0.005
+	}
+	if threshold <= 0 {
+		threshold = 50
+	}
+	return &PageHinkley{delta: delta, threshold: threshold}
+}
+
+// Reset resets state.
+func (m *PageHinkley) Reset() {
+	m.mu.Lock()
+	m.reset()
+	m.status = Status{}
+	m.mu.Unlock()
+}
+
+// Observe implements Detector.
+func (m *PageHinkley) Observe(value float64) State {
+	return m.ObserveAt(value, time.Now())
+}
+
+// ObserveAt implements Detector.
+func (m *PageHinkley) ObserveAt(value float64, t time.Time) State {
+	if !isValidSignal(value) {
+		return m.Status().State
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.n++
+	m.mean += (value - m.mean) / m.n
+	m.sum += value - m.mean - m.delta
+	if m.sum < m.lowest {
+		m.lowest = m.sum
+	}
+
+	state := StateStable
+	if m.n >= pageHinkleyMinObservations {
+		if stat := m.sum - m.lowest; stat > m.threshold {
+			state = StateDrift
+			m.reset()
+		} else if stat > m.threshold/2 {
+			state = StateWarning
+		}
+	}
+
+	m.status.transition(state, t)
+	return state
+}
+
+// Status implements Detector.
+func (m *PageHinkley) Status() Status {
+	m.mu.Lock()
+	status := m.status
+	m.mu.Unlock()
+	return status
+}
+
+func (m *PageHinkley) reset() {
+	m.n = 0
+	m.mean = 0
+	m.sum = 0
+	m.lowest = 0
+}
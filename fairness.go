@@ -0,0 +1,336 @@
+package mlmetrics
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// Fairness evaluates group fairness of binary classifiers, based on the confusion
+// counts of each group (e.g. a protected attribute such as gender or age band).
+// Category 1 is the positive, all other categories are negative outcomes.
+// Differences and ratios are calculated between the groups with the highest and the
+// lowest rates, groups for which a rate is undefined are ignored.
+// https://en.wikipedia.org/wiki/Fairness_(machine_learning)
+type Fairness struct {
+	rejector
+	groups map[string]*LabelCounts
+	mu     sync.RWMutex
+}
+
+// FairnessSnapshot is a point-in-time snapshot of a Fairness metric.
+type FairnessSnapshot struct {
+	Weight                      float64                // total weight observed
+	Groups                      map[string]LabelCounts // confusion counts of each group
+	DemographicParityDifference float64                // difference between highest and lowest selection rate
+	DemographicParityRatio      float64                // ratio between lowest and highest selection rate
+	EqualOpportunityDifference  float64                // difference between highest and lowest true positive rate
+	EqualizedOddsDifference     float64                // greater of true and false positive rate differences
+	PredictiveParityDifference  float64                // difference between highest and lowest positive predictive value
+	Rejected                    Rejections             // observations rejected by validation
+}
+
+// NewFairness inits a new metric.
+func NewFairness() *Fairness {
+	return &Fairness{groups: make(map[string]*LabelCounts)}
+}
+
+// Reset resets state.
+func (m *Fairness) Reset() {
+	m.mu.Lock()
+	m.reset()
+	m.mu.Unlock()
+	m.resetRejected()
+}
+
+// Observe records an observation of the actual vs the predicted category within a group.
+func (m *Fairness) Observe(group string, actual, predicted int) {
+	m.ObserveWeight(group, actual, predicted, 1.0)
+}
+
+// ObserveWeight records an observation of the actual vs the predicted category within a
+// group with a given weight.
+func (m *Fairness) ObserveWeight(group string, actual, predicted int, weight float64) {
+	_ = m.ObserveWeightStrict(group, actual, predicted, weight)
+}
+
+// ObserveWeightStrict is like ObserveWeight, but returns an error if the observation was
+// rejected by validation, see Rejected.
+func (m *Fairness) ObserveWeightStrict(group string, actual, predicted int, weight float64) error {
+	if err := validateCategories(actual, predicted, weight); err != nil {
+		return m.reject(err)
+	}
+
+	m.mu.Lock()
+	m.observe(group, actual, predicted, weight)
+	m.mu.Unlock()
+	return nil
+}
+
+// ObserveBatch records a batch of observations of actual vs predicted categories within
+// groups. Weights are optional, if given, they must match the number of observations.
+// Returns ErrLengthMismatch if the lengths of the inputs differ and a *BatchError
+// if observations were rejected.
+func (m *Fairness) ObserveBatch(groups []string, actual, predicted []int, weights []float64) error {
+	if err := validateBatch(len(groups), weights, len(actual), len(predicted)); err != nil {
+		return m.rejectBatch(len(groups), err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var berr BatchError
+	for i, group := range groups {
+		weight := batchWeight(weights, i)
+		if err := validateCategories(actual[i], predicted[i], weight); err != nil {
+			berr.add(i, m.reject(err))
+			continue
+		}
+		m.observe(group, actual[i], predicted[i], weight)
+	}
+	return berr.errOrNil()
+}
+
+// TotalWeight returns the total weight observed.
+func (m *Fairness) TotalWeight() float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var sum float64
+	for _, c := range m.groups {
+		sum += c.TP + c.FP + c.FN + c.TN
+	}
+	return sum
+}
+
+// Groups returns the sorted names of all groups.
+func (m *Fairness) Groups() []string {
+	m.mu.RLock()
+	groups := make([]string, 0, len(m.groups))
+	for group := range m.groups {
+		groups = append(groups, group)
+	}
+	m.mu.RUnlock()
+
+	sort.Strings(groups)
+	return groups
+}
+
+// Counts returns the binary confusion counts of a group.
+func (m *Fairness) Counts(group string) LabelCounts {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if c, ok := m.groups[group]; ok {
+		return *c
+	}
+	return LabelCounts{}
+}
+
+// SelectionRate calculates the rate of positive predictions within a group.
+func (m *Fairness) SelectionRate(group string) float64 {
+	rate, _ := selectionRate(m.Counts(group))
+	return rate
+}
+
+// TruePositiveRate calculates the true positive rate (aka sensitivity) within a group.
+func (m *Fairness) TruePositiveRate(group string) float64 {
+	rate, _ := truePositiveRate(m.Counts(group))
+	return rate
+}
+
+// FalsePositiveRate calculates the false positive rate within a group.
+func (m *Fairness) FalsePositiveRate(group string) float64 {
+	rate, _ := falsePositiveRate(m.Counts(group))
+	return rate
+}
+
+// PositivePredictiveValue calculates the positive predictive value (aka precision)
+// within a group.
+func (m *Fairness) PositivePredictiveValue(group string) float64 {
+	rate, _ := positivePredictiveValue(m.Counts(group))
+	return rate
+}
+
+// DemographicParityDifference calculates the difference between the highest and the
+// lowest selection rate across groups. A value of 0 indicates demographic (aka
+// statistical) parity.
+func (m *Fairness) DemographicParityDifference() float64 {
+	lo, hi := m.extremes(selectionRate)
+	return hi - lo
+}
+
+// DemographicParityRatio calculates the ratio between the lowest and the highest
+// selection rate across groups. A value of 1 indicates demographic parity.
+func (m *Fairness) DemographicParityRatio() float64 {
+	lo, hi := m.extremes(selectionRate)
+	if hi > 0 {
+		return lo / hi
+	}
+	return 0.0
+}
+
+// EqualOpportunityDifference calculates the difference between the highest and the
+// lowest true positive rate across groups.
+func (m *Fairness) EqualOpportunityDifference() float64 {
+	lo, hi := m.extremes(truePositiveRate)
+	return hi - lo
+}
+
+// EqualizedOddsDifference calculates the greater of the differences between the
+// highest and the lowest true positive and false positive rates across groups.
+func (m *Fairness) EqualizedOddsDifference() float64 {
+	tprLo, tprHi := m.extremes(truePositiveRate)
+	fprLo, fprHi := m.extremes(falsePositiveRate)
+	return math.Max(tprHi-tprLo, fprHi-fprLo)
+}
+
+// PredictiveParityDifference calculates the difference between the highest and the
+// lowest positive predictive value across groups.
+func (m *Fairness) PredictiveParityDifference() float64 {
+	lo, hi := m.extremes(positivePredictiveValue)
+	return hi - lo
+}
+
+// DisparateImpact calculates the lowest ratio of the selection rate of any other
+// group to the selection rate of the reference (usually the privileged) group.
+// Values below 0.8 are commonly considered evidence of adverse impact.
+// https://en.wikipedia.org/wiki/Disparate_impact
+func (m *Fairness) DisparateImpact(reference string) float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ref, ok := m.groups[reference]
+	if !ok {
+		return 0.0
+	}
+	refRate, ok := selectionRate(*ref)
+	if !ok || refRate == 0 {
+		return 0.0
+	}
+
+	lowest := math.Inf(1)
+	for group, c := range m.groups {
+		if group == reference {
+			continue
+		}
+		if rate, ok := selectionRate(*c); ok {
+			lowest = math.Min(lowest, rate/refRate)
+		}
+	}
+	if math.IsInf(lowest, 1) {
+		return 1.0
+	}
+	return lowest
+}
+
+// Snapshot returns a consistent point-in-time snapshot of all values.
+func (m *Fairness) Snapshot() FairnessSnapshot {
+	m.mu.RLock()
+	c := m.clone()
+	m.mu.RUnlock()
+
+	snap := c.snapshot()
+	snap.Rejected = m.Rejected()
+	return snap
+}
+
+// SnapshotAndReset atomically returns a snapshot of all values and resets state.
+func (m *Fairness) SnapshotAndReset() FairnessSnapshot {
+	m.mu.Lock()
+	c := m.clone()
+	m.reset()
+	m.mu.Unlock()
+
+	snap := c.snapshot()
+	snap.Rejected = m.harvestRejected()
+	return snap
+}
+
+func (m *Fairness) observe(group string, actual, predicted int, weight float64) {
+	c, ok := m.groups[group]
+	if !ok {
+		c = new(LabelCounts)
+		m.groups[group] = c
+	}
+
+	switch {
+	case actual == 1 && predicted == 1:
+		c.TP += weight
+	case predicted == 1:
+		c.FP += weight
+	case actual == 1:
+		c.FN += weight
+	default:
+		c.TN += weight
+	}
+}
+
+func (m *Fairness) clone() *Fairness {
+	c := &Fairness{groups: make(map[string]*LabelCounts, len(m.groups))}
+	for group, counts := range m.groups {
+		cp := *counts
+		c.groups[group] = &cp
+	}
+	return c
+}
+
+func (m *Fairness) reset() {
+	m.groups = make(map[string]*LabelCounts)
+}
+
+func (m *Fairness) snapshot() FairnessSnapshot {
+	s := FairnessSnapshot{
+		Weight:                      m.TotalWeight(),
+		Groups:                      make(map[string]LabelCounts, len(m.groups)),
+		DemographicParityDifference: m.DemographicParityDifference(),
+		DemographicParityRatio:      m.DemographicParityRatio(),
+		EqualOpportunityDifference:  m.EqualOpportunityDifference(),
+		EqualizedOddsDifference:     m.EqualizedOddsDifference(),
+		PredictiveParityDifference:  m.PredictiveParityDifference(),
+	}
+	for group, counts := range m.groups {
+		s.Groups[group] = *counts
+	}
+	return s
+}
+
+func (m *Fairness) extremes(fn func(LabelCounts) (float64, bool)) (lo, hi float64) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for _, c := range m.groups {
+		if rate, ok := fn(*c); ok {
+			lo = math.Min(lo, rate)
+			hi = math.Max(hi, rate)
+		}
+	}
+	if lo > hi {
+		return 0, 0
+	}
+	return
+}
+
+func selectionRate(c LabelCounts) (float64, bool) {
+	return fairnessRatio(c.TP+c.FP, c.TP+c.FP+c.FN+c.TN)
+}
+
+func truePositiveRate(c LabelCounts) (float64, bool) {
+	return fairnessRatio(c.TP, c.TP+c.FN)
+}
+
+func falsePositiveRate(c LabelCounts) (float64, bool) {
+	return fairnessRatio(c.FP, c.FP+c.TN)
+}
+
+func positivePredictiveValue(c LabelCounts) (float64, bool) {
+	return fairnessRatio(c.TP, c.TP+c.FP)
+}
+
+func fairnessRatio(num, div float64) (float64, bool) {
+	if div > 0 {
+		return num / div, true
+	}
+	return 0.0, false
+}
@@ -0,0 +1,111 @@
+package mlmetrics_test
+
+import (
+	. "github.com/bsm/ginkgo"
+	. "github.com/bsm/gomega"
+	"github.com/bsm/mlmetrics"
+)
+
+var _ = Describe("Fairness", func() {
+	var subject *mlmetrics.Fairness
+
+	observe := func(group string, actual, predicted int, n int) {
+		for i := 0; i < n; i++ {
+			subject.Observe(group, actual, predicted)
+		}
+	}
+
+	BeforeEach(func() {
+		subject = mlmetrics.NewFairness()
+		observe("a", 1, 1, 3)
+		observe("a", 0, 1, 1)
+		observe("a", 1, 0, 1)
+		observe("a", 0, 0, 5)
+		observe("b", 1, 1, 1)
+		subject.ObserveWeight("b", 0, 1, 1.0)
+		subject.ObserveWeight("b", 1, 0, 2.0)
+		subject.ObserveWeight("b", 0, 0, 6.0)
+	})
+
+	It("should calculate per-group rates", func() {
+		Expect(subject.TotalWeight()).To(Equal(20.0))
+		Expect(subject.Groups()).To(Equal([]string{"a", "b"}))
+		Expect(subject.Counts("a")).To(Equal(mlmetrics.LabelCounts{TP: 3, FP: 1, FN: 1, TN: 5}))
+		Expect(subject.Counts("x")).To(Equal(mlmetrics.LabelCounts{}))
+
+		Expect(subject.SelectionRate("a")).To(Equal(0.4))
+		Expect(subject.SelectionRate("b")).To(Equal(0.2))
+		Expect(subject.TruePositiveRate("a")).To(Equal(0.75))
+		Expect(subject.TruePositiveRate("b")).To(BeNumerically("~", 0.333, 0.001))
+		Expect(subject.FalsePositiveRate("a")).To(BeNumerically("~", 0.167, 0.001))
+		Expect(subject.FalsePositiveRate("b")).To(BeNumerically("~", 0.143, 0.001))
+		Expect(subject.PositivePredictiveValue("a")).To(Equal(0.75))
+		Expect(subject.PositivePredictiveValue("b")).To(Equal(0.5))
+	})
+
+	It("should calculate fairness scores", func() {
+		Expect(subject.DemographicParityDifference()).To(BeNumerically("~", 0.2, 0.001))
+		Expect(subject.DemographicParityRatio()).To(BeNumerically("~", 0.5, 0.001))
+		Expect(subject.EqualOpportunityDifference()).To(BeNumerically("~", 0.417, 0.001))
+		Expect(subject.EqualizedOddsDifference()).To(BeNumerically("~", 0.417, 0.001))
+		Expect(subject.PredictiveParityDifference()).To(BeNumerically("~", 0.25, 0.001))
+		Expect(subject.DisparateImpact("a")).To(BeNumerically("~", 0.5, 0.001))
+		Expect(subject.DisparateImpact("b")).To(BeNumerically("~", 2.0, 0.001))
+		Expect(subject.DisparateImpact("x")).To(Equal(0.0))
+	})
+
+	It("should ignore groups with undefined rates", func() {
+		subject.Observe("c", 0, 0)
+		Expect(subject.EqualOpportunityDifference()).To(BeNumerically("~", 0.417, 0.001))
+		Expect(subject.PredictiveParityDifference()).To(BeNumerically("~", 0.25, 0.001))
+		Expect(subject.DemographicParityDifference()).To(BeNumerically("~", 0.4, 0.001))
+	})
+
+	It("should treat non-positive categories as negative", func() {
+		subject.Reset()
+		subject.Observe("a", 2, 1)
+		subject.Observe("a", 1, 3)
+		subject.Observe("a", -1, 1)
+		Expect(subject.Counts("a")).To(Equal(mlmetrics.LabelCounts{FP: 1, FN: 1}))
+	})
+
+	It("should handle blanks", func() {
+		subject.Reset()
+		Expect(subject.TotalWeight()).To(Equal(0.0))
+		Expect(subject.Groups()).To(BeEmpty())
+		Expect(subject.DemographicParityDifference()).To(Equal(0.0))
+		Expect(subject.DemographicParityRatio()).To(Equal(0.0))
+		Expect(subject.EqualizedOddsDifference()).To(Equal(0.0))
+	})
+
+	It("should snapshot", func() {
+		snap := subject.Snapshot()
+		Expect(snap).To(Equal(mlmetrics.FairnessSnapshot{
+			Weight: 20,
+			Groups: map[string]mlmetrics.LabelCounts{
+				"a": {TP: 3, FP: 1, FN: 1, TN: 5},
+				"b": {TP: 1, FP: 1, FN: 2, TN: 6},
+			},
+			DemographicParityDifference: subject.DemographicParityDifference(),
+			DemographicParityRatio:      subject.DemographicParityRatio(),
+			EqualOpportunityDifference:  subject.EqualOpportunityDifference(),
+			EqualizedOddsDifference:     subject.EqualizedOddsDifference(),
+			PredictiveParityDifference:  subject.PredictiveParityDifference(),
+		}))
+
+		subject.Observe("a", 1, 1)
+		Expect(snap.Groups["a"].TP).To(Equal(3.0))
+	})
+
+	It("should observe batches", func() {
+		expected := mlmetrics.NewFairness()
+		expected.Observe("a", 1, 1)
+		expected.Observe("b", 0, 1)
+		expected.ObserveWeight("a", 1, 0, 2)
+
+		subject = mlmetrics.NewFairness()
+		Expect(subject.ObserveBatch([]string{"a", "b", "a", "b"}, []int{1, 0, 1, -1}, []int{1, 1, 0, 0}, []float64{1, 1, 2, 1})).To(MatchError(mlmetrics.ErrInvalidCategory))
+		Expect(subject.Snapshot()).To(equalSnapshot(expected.Snapshot()))
+		Expect(subject.ObserveBatch([]string{"a"}, []int{1}, []int{}, nil)).To(MatchError(mlmetrics.ErrLengthMismatch))
+	})
+})
@@ -0,0 +1,373 @@
+package mlmetrics
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// Gains evaluates scoring models (e.g. propensity or risk scores) for binary
+// outcomes. It reports lift tables, cumulative gains, the Kolmogorov-Smirnov
+// statistic and the Gini coefficient. All observations are retained.
+type Gains struct {
+	rejector
+
+	points []gainsPoint
+	sorted bool
+
+	mu sync.RWMutex
+}
+
+// GainsSnapshot is a point-in-time snapshot of a Gains metric.
+type GainsSnapshot struct {
+	Weight   float64    // total weight observed
+	KS       float64    // Kolmogorov-Smirnov statistic
+	AUC      float64    // area under the ROC curve
+	Gini     float64    // Gini coefficient
+	Rejected Rejections // observations rejected by validation
+}
+
+type gainsPoint struct {
+	score  float64
+	weight float64
+	pos    bool
+}
+
+// GainsBucket is a row of a lift table. Buckets are ordered by descending score.
+type GainsBucket struct {
+	MinScore  float64 // lowest score in the bucket
+	MaxScore  float64 // highest score in the bucket
+	Weight    float64 // total weight in the bucket
+	Positives float64 // weight of positive outcomes in the bucket
+
+	Rate           float64 // rate of positive outcomes in the bucket
+	Lift           float64 // bucket rate divided by overall rate
+	CumulativeGain float64 // share of all positives captured up to and including this bucket
+	CumulativeLift float64 // cumulative rate divided by overall rate
+}
+
+// GainsPoint is a point on the cumulative gains curve.
+type GainsPoint struct {
+	Threshold float64 // score threshold, observations at or above are targeted
+	Targeted  float64 // share of the total weight targeted
+	Gain      float64 // share of positive outcomes captured
+}
+
+// NewGains inits a new metric.
+func NewGains() *Gains {
+	return &Gains{}
+}
+
+// Reset resets state.
+func (m *Gains) Reset() {
+	m.mu.Lock()
+	m.reset()
+	m.mu.Unlock()
+	m.resetRejected()
+}
+
+// Observe records an observation of a score vs the actual outcome.
+func (m *Gains) Observe(score float64, positive bool) {
+	m.ObserveWeight(score, positive, 1.0)
+}
+
+// ObserveWeight records an observation of a score vs the actual outcome with a given weight.
+func (m *Gains) ObserveWeight(score float64, positive bool, weight float64) {
+	_ = m.ObserveWeightStrict(score, positive, weight)
+}
+
+// ObserveWeightStrict is like ObserveWeight, but returns an error if the observation was
+// rejected by validation, see Rejected.
+func (m *Gains) ObserveWeightStrict(score float64, positive bool, weight float64) error {
+	if err := validateGains(score, weight); err != nil {
+		return m.reject(err)
+	}
+
+	m.mu.Lock()
+	m.observe(score, positive, weight)
+	m.mu.Unlock()
+	return nil
+}
+
+// ObserveBatch records a batch of observations of scores vs actual outcomes.
+// Weights are optional, if given, they must match the number of observations.
+// Returns ErrLengthMismatch if the lengths of the inputs differ and a *BatchError
+// if observations were rejected.
+func (m *Gains) ObserveBatch(scores []float64, positives []bool, weights []float64) error {
+	if err := validateBatch(len(scores), weights, len(positives)); err != nil {
+		return m.rejectBatch(len(scores), err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var berr BatchError
+	for i, score := range scores {
+		weight := batchWeight(weights, i)
+		if err := validateGains(score, weight); err != nil {
+			berr.add(i, m.reject(err))
+			continue
+		}
+		m.observe(score, positives[i], weight)
+	}
+	return berr.errOrNil()
+}
+
+// TotalWeight returns the total weight observed.
+func (m *Gains) TotalWeight() float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var sum float64
+	for _, p := range m.points {
+		sum += p.weight
+	}
+	return sum
+}
+
+// LiftTable returns a lift table with n buckets of (approximately) equal weight,
+// ordered by descending score. Use n=10 for deciles. Empty buckets are omitted, this
+// happens when there are fewer distinct observations than buckets.
+func (m *Gains) LiftTable(n int) []GainsBucket {
+	points := m.sortedPoints()
+	if n < 1 || len(points) == 0 {
+		return nil
+	}
+
+	total, positives := gainsTotals(points)
+	if total == 0 {
+		return nil
+	}
+	rate := positives / total
+
+	buckets := make([]GainsBucket, n)
+	var cum float64
+	for _, p := range points {
+		// assign by the mid-point of the observation's weight
+		pos := int((cum + p.weight/2) / total * float64(n))
+		if pos >= n {
+			pos = n - 1
+		}
+		cum += p.weight
+
+		b := &buckets[pos]
+		if b.Weight == 0 {
+			b.MaxScore = p.score
+		}
+		b.MinScore = p.score
+		b.Weight += p.weight
+		if p.pos {
+			b.Positives += p.weight
+		}
+	}
+
+	// remove empty buckets
+	res := buckets[:0]
+	for _, b := range buckets {
+		if b.Weight > 0 {
+			res = append(res, b)
+		}
+	}
+	buckets = res
+
+	var cumWeight, cumPositives float64
+	for i := range buckets {
+		b := &buckets[i]
+		cumWeight += b.Weight
+		cumPositives += b.Positives
+
+		b.Rate = b.Positives / b.Weight
+		if rate > 0 {
+			b.Lift = b.Rate / rate
+			b.CumulativeLift = cumPositives / cumWeight / rate
+		}
+		if positives > 0 {
+			b.CumulativeGain = cumPositives / positives
+		}
+	}
+	return buckets
+}
+
+// GainsCurve returns the cumulative gains curve, with one point per distinct score,
+// ordered by descending score.
+func (m *Gains) GainsCurve() []GainsPoint {
+	points := m.sortedPoints()
+	total, positives := gainsTotals(points)
+	if total == 0 {
+		return nil
+	}
+
+	curve := make([]GainsPoint, 0, len(points))
+	var cumWeight, cumPositives float64
+	for i, p := range points {
+		cumWeight += p.weight
+		if p.pos {
+			cumPositives += p.weight
+		}
+		if i+1 < len(points) && points[i+1].score == p.score {
+			continue
+		}
+
+		pt := GainsPoint{Threshold: p.score, Targeted: cumWeight / total}
+		if positives > 0 {
+			pt.Gain = cumPositives / positives
+		}
+		curve = append(curve, pt)
+	}
+	return curve
+}
+
+// KS calculates the Kolmogorov-Smirnov statistic, the maximum separation between the
+// cumulative score distributions of positive and negative outcomes.
+// https://en.wikipedia.org/wiki/Kolmogorov%E2%80%93Smirnov_test
+func (m *Gains) KS() float64 {
+	points := m.sortedPoints()
+	total, positives := gainsTotals(points)
+	negatives := total - positives
+	if positives == 0 || negatives == 0 {
+		return 0.0
+	}
+
+	var ks, cumPos, cumNeg float64
+	for i, p := range points {
+		if p.pos {
+			cumPos += p.weight
+		} else {
+			cumNeg += p.weight
+		}
+		if i+1 < len(points) && points[i+1].score == p.score {
+			continue
+		}
+		if d := math.Abs(cumPos/positives - cumNeg/negatives); d > ks {
+			ks = d
+		}
+	}
+	return ks
+}
+
+// AUC calculates the area under the ROC curve, the probability that a random
+// positive outcome is scored higher than a random negative outcome.
+// https://en.wikipedia.org/wiki/Receiver_operating_characteristic#Area_under_the_curve
+func (m *Gains) AUC() float64 {
+	auc, _ := m.auc()
+	return auc
+}
+
+// Gini calculates the Gini coefficient (aka accuracy ratio or Somers' D), 2*AUC - 1.
+func (m *Gains) Gini() float64 {
+	if auc, ok := m.auc(); ok {
+		return 2*auc - 1
+	}
+	return 0.0
+}
+
+// Snapshot returns a consistent point-in-time snapshot of all values.
+func (m *Gains) Snapshot() GainsSnapshot {
+	m.mu.RLock()
+	c := m.clone()
+	m.mu.RUnlock()
+
+	snap := c.snapshot()
+	snap.Rejected = m.Rejected()
+	return snap
+}
+
+// SnapshotAndReset atomically returns a snapshot of all values and resets state.
+func (m *Gains) SnapshotAndReset() GainsSnapshot {
+	m.mu.Lock()
+	c := m.clone()
+	m.reset()
+	m.mu.Unlock()
+
+	snap := c.snapshot()
+	snap.Rejected = m.harvestRejected()
+	return snap
+}
+
+func (m *Gains) observe(score float64, positive bool, weight float64) {
+	m.points = append(m.points, gainsPoint{score: score, weight: weight, pos: positive})
+	m.sorted = false
+}
+
+func (m *Gains) clone() *Gains {
+	return &Gains{
+		points: append([]gainsPoint(nil), m.points...),
+		sorted: m.sorted,
+	}
+}
+
+func (m *Gains) reset() {
+	m.points = m.points[:0]
+	m.sorted = true
+}
+
+func (m *Gains) snapshot() GainsSnapshot {
+	return GainsSnapshot{
+		Weight: m.TotalWeight(),
+		KS:     m.KS(),
+		AUC:    m.AUC(),
+		Gini:   m.Gini(),
+	}
+}
+
+func (m *Gains) auc() (float64, bool) {
+	points := m.sortedPoints()
+	total, positives := gainsTotals(points)
+	negatives := total - positives
+	if positives == 0 || negatives == 0 {
+		return 0.0, false
+	}
+
+	// count weight of positive-negative pairs where the positive is scored higher,
+	// ties count half
+	var pairs, cumNeg, tiePos, tieNeg float64
+	for i := len(points) - 1; i >= 0; i-- {
+		p := points[i]
+		if p.pos {
+			tiePos += p.weight
+		} else {
+			tieNeg += p.weight
+		}
+		if i > 0 && points[i-1].score == p.score {
+			continue
+		}
+
+		pairs += tiePos*cumNeg + tiePos*tieNeg/2
+		cumNeg += tieNeg
+		tiePos, tieNeg = 0, 0
+	}
+	return pairs / (positives * negatives), true
+}
+
+// sortedPoints returns a copy of points sorted by descending score.
+func (m *Gains) sortedPoints() []gainsPoint {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.sorted {
+		sort.SliceStable(m.points, func(i, j int) bool { return m.points[i].score > m.points[j].score })
+		m.sorted = true
+	}
+
+	points := make([]gainsPoint, len(m.points))
+	copy(points, m.points)
+	return points
+}
+
+func gainsTotals(points []gainsPoint) (total, positives float64) {
+	for _, p := range points {
+		total += p.weight
+		if p.pos {
+			positives += p.weight
+		}
+	}
+	return
+}
+
+// validateGains validates an observation of a score.
+func validateGains(score, weight float64) error {
+	if !isValidNumeric(score) {
+		return ErrInvalidNumeric
+	}
+	return validateWeight(weight)
+}
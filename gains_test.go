@@ -0,0 +1,153 @@
+package mlmetrics_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	. "github.com/bsm/ginkgo"
+	. "github.com/bsm/gomega"
+	"github.com/bsm/mlmetrics"
+)
+
+var _ = Describe("Gains", func() {
+	var subject *mlmetrics.Gains
+
+	BeforeEach(func() {
+		subject = mlmetrics.NewGains()
+		outcomes := []bool{false, false, false, true, false, false, true, false, true, true}
+		for i, positive := range outcomes {
+			subject.Observe(float64(i)/10, positive)
+		}
+	})
+
+	It("should calculate lift tables", func() {
+		Expect(subject.TotalWeight()).To(Equal(10.0))
+
+		table := subject.LiftTable(5)
+		Expect(table).To(HaveLen(5))
+		Expect(table[0]).To(Equal(mlmetrics.GainsBucket{
+			MinScore:       0.8,
+			MaxScore:       0.9,
+			Weight:         2,
+			Positives:      2,
+			Rate:           1,
+			Lift:           2.5,
+			CumulativeGain: 0.5,
+			CumulativeLift: 2.5,
+		}))
+
+		var gains, lifts []float64
+		for _, b := range table {
+			gains = append(gains, b.CumulativeGain)
+			lifts = append(lifts, b.Lift)
+		}
+		Expect(gains).To(Equal([]float64{0.5, 0.75, 0.75, 1, 1}))
+		Expect(lifts).To(Equal([]float64{2.5, 1.25, 0, 1.25, 0}))
+	})
+
+	It("should calculate gains curves", func() {
+		subject.Observe(0.9, false)
+
+		curve := subject.GainsCurve()
+		Expect(curve).To(HaveLen(10))
+		Expect(curve[0].Threshold).To(Equal(0.9))
+		Expect(curve[0].Targeted).To(BeNumerically("~", 0.182, 0.001))
+		Expect(curve[0].Gain).To(BeNumerically("~", 0.25, 0.001))
+		Expect(curve[9]).To(Equal(mlmetrics.GainsPoint{Threshold: 0, Targeted: 1, Gain: 1}))
+	})
+
+	It("should calculate KS", func() {
+		Expect(subject.KS()).To(BeNumerically("~", 0.583, 0.001))
+	})
+
+	It("should calculate AUC and Gini", func() {
+		Expect(subject.AUC()).To(BeNumerically("~", 0.833, 0.001))
+		Expect(subject.Gini()).To(BeNumerically("~", 0.667, 0.001))
+
+		subject.ObserveWeight(0.95, false, 4)
+		Expect(subject.AUC()).To(BeNumerically("~", 0.5, 0.001))
+		Expect(subject.Gini()).To(BeNumerically("~", 0.0, 0.001))
+	})
+
+	It("should handle ties", func() {
+		subject.Reset()
+		subject.Observe(0.5, true)
+		subject.Observe(0.5, false)
+		Expect(subject.AUC()).To(BeNumerically("~", 0.5, 0.001))
+		Expect(subject.KS()).To(BeNumerically("~", 0.0, 0.001))
+	})
+
+	It("should handle inverse scores", func() {
+		subject.Reset()
+		subject.Observe(0.1, true)
+		subject.Observe(0.9, false)
+		Expect(subject.AUC()).To(Equal(0.0))
+		Expect(subject.Gini()).To(Equal(-1.0))
+	})
+
+	It("should omit empty buckets", func() {
+		subject.Reset()
+		subject.Observe(0.9, true)
+		subject.Observe(0.1, false)
+
+		table := subject.LiftTable(10)
+		Expect(table).To(Equal([]mlmetrics.GainsBucket{
+			{MinScore: 0.9, MaxScore: 0.9, Weight: 1, Positives: 1, Rate: 1, Lift: 2, CumulativeLift: 2, CumulativeGain: 1},
+			{MinScore: 0.1, MaxScore: 0.1, Weight: 1, Positives: 0, Rate: 0, Lift: 0, CumulativeLift: 1, CumulativeGain: 1},
+		}))
+		_, err := json.Marshal(table)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should handle blanks", func() {
+		subject.Reset()
+		Expect(subject.TotalWeight()).To(Equal(0.0))
+		Expect(subject.LiftTable(10)).To(BeNil())
+		Expect(subject.GainsCurve()).To(BeNil())
+		Expect(subject.KS()).To(Equal(0.0))
+		Expect(subject.AUC()).To(Equal(0.0))
+		Expect(subject.Gini()).To(Equal(0.0))
+	})
+
+	It("should observe batches", func() {
+		expected := mlmetrics.NewGains()
+		expected.Observe(0.9, true)
+		expected.ObserveWeight(0.4, false, 2)
+
+		subject = mlmetrics.NewGains()
+		Expect(subject.ObserveBatch([]float64{0.9, 0.4, math.NaN()}, []bool{true, false, true}, []float64{1, 2, 1})).To(MatchError(mlmetrics.ErrInvalidNumeric))
+		Expect(subject.Snapshot()).To(equalSnapshot(expected.Snapshot()))
+		Expect(subject.ObserveBatch([]float64{0.9}, []bool{}, nil)).To(MatchError(mlmetrics.ErrLengthMismatch))
+	})
+})
+
+func ExampleGains() {
+	scores := []float64{0.9, 0.8, 0.75, 0.7, 0.6, 0.4, 0.3, 0.25, 0.2, 0.1}
+	outcomes := []bool{true, true, false, true, false, true, false, false, false, false}
+
+	metric := mlmetrics.NewGains()
+	for i := range scores {
+		metric.Observe(scores[i], outcomes[i])
+	}
+
+	// print quintile lift table
+	for i, b := range metric.LiftTable(5) {
+		fmt.Printf("%d: lift=%.2f gain=%.2f\n", i+1, b.Lift, b.CumulativeGain)
+	}
+
+	// print metrics
+	fmt.Println()
+	fmt.Printf("ks   : %.3f\n", metric.KS())
+	fmt.Printf("gini : %.3f\n", metric.Gini())
+
+	// Output:
+	// 1: lift=2.50 gain=0.50
+	// 2: lift=1.25 gain=0.75
+	// 3: lift=1.25 gain=1.00
+	// 4: lift=0.00 gain=1.00
+	// 5: lift=0.00 gain=1.00
+	//
+	// ks   : 0.667
+	// gini : 0.750
+}
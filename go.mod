@@ -1,6 +1,6 @@
 module github.com/bsm/mlmetrics
 
-go 1.14
+go 1.18
 
 require (
 	github.com/bsm/ginkgo v1.16.4
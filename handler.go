@@ -0,0 +1,417 @@
+package mlmetrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HandlerOptions contains options for the Handler.
+type HandlerOptions struct {
+	// AllowReset enables reset endpoints. Default: false.
+	AllowReset bool
+}
+
+// Handler is an http.Handler which serves the current values of registered metrics
+// as JSON. It supports the following endpoints:
+//
+//	GET  /            values of all metrics
+//	GET  /NAME        values of a single metric
+//	POST /reset       resets all metrics (if enabled)
+//	POST /NAME/reset  resets a single metric (if enabled)
+//
+// Use http.StripPrefix to mount the handler under a path.
+type Handler struct {
+	opt     HandlerOptions
+	metrics map[string]interface{}
+	mu      sync.RWMutex
+}
+
+// NewHandler inits a new handler.
+func NewHandler(opt *HandlerOptions) *Handler {
+	h := &Handler{metrics: make(map[string]interface{})}
+	if opt != nil {
+		h.opt = *opt
+	}
+	return h
+}
+
+// Register registers a metric by name. Supported metrics are all metrics of this
+// package which provide a Snapshot, as well as Windowed and Segmented containers of
+// these. Use RegisterFunc for other values. Register panics if the metric is not
+// supported.
+func (h *Handler) Register(name string, metric interface{}) {
+	if !isHandlerMetric(metric) {
+		panic(fmt.Sprintf("mlmetrics: cannot register unsupported metric type %T", metric))
+	}
+
+	h.mu.Lock()
+	h.metrics[name] = metric
+	h.mu.Unlock()
+}
+
+// RegisterFunc registers a custom function by name. The function is called on every
+// request and must return a value that can be encoded as JSON.
+func (h *Handler) RegisterFunc(name string, fn func() interface{}) {
+	h.Register(name, handlerFunc(fn))
+}
+
+// Unregister removes a registered metric.
+func (h *Handler) Unregister(name string) {
+	h.mu.Lock()
+	delete(h.metrics, name)
+	h.mu.Unlock()
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+
+	if path == "reset" || strings.HasSuffix(path, "/reset") {
+		h.serveReset(w, r, strings.TrimSuffix(strings.TrimSuffix(path, "reset"), "/"))
+		return
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if path == "" {
+		h.mu.RLock()
+		res := make(map[string]interface{}, len(h.metrics))
+		for name, metric := range h.metrics {
+			res[name] = handlerValue(metric)
+		}
+		h.mu.RUnlock()
+
+		writeJSON(w, http.StatusOK, res)
+		return
+	}
+
+	h.mu.RLock()
+	metric, ok := h.metrics[path]
+	h.mu.RUnlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, handlerValue(metric))
+}
+
+func (h *Handler) serveReset(w http.ResponseWriter, r *http.Request, name string) {
+	if !h.opt.AllowReset {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if name == "" {
+		for _, metric := range h.metrics {
+			if m, ok := metric.(interface{ Reset() }); ok {
+				m.Reset()
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	metric, ok := h.metrics[name]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	m, ok := metric.(interface{ Reset() })
+	if !ok {
+		http.Error(w, "metric cannot be reset", http.StatusUnprocessableEntity)
+		return
+	}
+	m.Reset()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// --------------------------------------------------------------------
+
+type handlerFunc func() interface{}
+
+// windowedMetric is implemented by Windowed.
+type windowedMetric interface {
+	windowViews() []Window[interface{}]
+	zeroMetric() interface{}
+}
+
+// segmentedMetric is implemented by Segmented.
+type segmentedMetric interface {
+	segmentViews() map[string]interface{}
+	zeroMetric() interface{}
+}
+
+type handlerWindow struct {
+	Start  time.Time   `json:"start"`
+	End    time.Time   `json:"end"`
+	Values interface{} `json:"values"`
+}
+
+// isHandlerMetric returns true if handlerValue supports the metric. Containers are
+// checked by the zero value of their metric type.
+func isHandlerMetric(metric interface{}) bool {
+	switch m := metric.(type) {
+	case handlerFunc, *Accuracy, *ConfusionMatrix, *Regression, *LogLoss,
+		*MultiRegression, *Deviance, *HuberLoss, *QuantileLoss, *IntervalCoverage,
+		*Correlation, *Gains, *Clustering, *MultiLabel, *Fairness, *Ranking, *Recommendations:
+		return true
+	case windowedMetric:
+		return isHandlerMetric(m.zeroMetric())
+	case segmentedMetric:
+		return isHandlerMetric(m.zeroMetric())
+	}
+	return false
+}
+
+// handlerValue returns the JSON representation of a metric.
+func handlerValue(metric interface{}) interface{} {
+	switch m := metric.(type) {
+	case handlerFunc:
+		return m()
+	case *Accuracy:
+		s := m.Snapshot()
+		return map[string]interface{}{
+			"weight":  jsonFloat(s.Weight),
+			"correct": jsonFloat(s.Correct),
+			"rate":    jsonFloat(s.Rate),
+		}
+	case *ConfusionMatrix:
+		return confusionValue(m.Snapshot())
+	case *Regression:
+		return regressionValue(m.Snapshot())
+	case *LogLoss:
+		return map[string]interface{}{
+			"score": jsonFloat(m.Snapshot().Score),
+		}
+	case *MultiRegression:
+		s := m.Snapshot()
+		outputs := make([]interface{}, len(s.Outputs))
+		for x, o := range s.Outputs {
+			outputs[x] = regressionValue(o)
+		}
+		return map[string]interface{}{
+			"outputs":      outputs,
+			"average_mae":  jsonFloat(s.AverageMAE),
+			"average_rmse": jsonFloat(s.AverageRMSE),
+			"average_r2":   jsonFloat(s.AverageR2),
+		}
+	case *Deviance:
+		s := m.Snapshot()
+		return map[string]interface{}{
+			"power":         jsonFloat(s.Power),
+			"weight":        jsonFloat(s.Weight),
+			"score":         jsonFloat(s.Score),
+			"out_of_domain": s.OutOfDomain,
+		}
+	case *HuberLoss:
+		s := m.Snapshot()
+		return map[string]interface{}{
+			"weight": jsonFloat(s.Weight),
+			"score":  jsonFloat(s.Score),
+		}
+	case *QuantileLoss:
+		s := m.Snapshot()
+		return map[string]interface{}{
+			"weight":     jsonFloat(s.Weight),
+			"score":      jsonFloat(s.Score),
+			"below_rate": jsonFloat(s.BelowRate),
+		}
+	case *IntervalCoverage:
+		s := m.Snapshot()
+		return map[string]interface{}{
+			"weight":     jsonFloat(s.Weight),
+			"rate":       jsonFloat(s.Rate),
+			"mean_width": jsonFloat(s.MeanWidth),
+			"winkler":    jsonFloat(s.Winkler),
+		}
+	case *Correlation:
+		s := m.Snapshot()
+		return map[string]interface{}{
+			"weight":   jsonFloat(s.Weight),
+			"pearson":  jsonFloat(s.Pearson),
+			"spearman": jsonFloat(s.Spearman),
+			"kendall":  jsonFloat(s.Kendall),
+		}
+	case *Gains:
+		s := m.Snapshot()
+		return map[string]interface{}{
+			"weight": jsonFloat(s.Weight),
+			"ks":     jsonFloat(s.KS),
+			"auc":    jsonFloat(s.AUC),
+			"gini":   jsonFloat(s.Gini),
+		}
+	case *Clustering:
+		s := m.Snapshot()
+		return map[string]interface{}{
+			"weight":                 jsonFloat(s.Weight),
+			"adjusted_rand":          jsonFloat(s.AdjustedRand),
+			"mutual_info":            jsonFloat(s.MutualInfo),
+			"normalized_mutual_info": jsonFloat(s.NormalizedMutualInfo),
+			"adjusted_mutual_info":   jsonFloat(s.AdjustedMutualInfo),
+			"homogeneity":            jsonFloat(s.Homogeneity),
+			"completeness":           jsonFloat(s.Completeness),
+			"v_measure":              jsonFloat(s.VMeasure),
+			"fowlkes_mallows":        jsonFloat(s.FowlkesMallows),
+		}
+	case *MultiLabel:
+		s := m.Snapshot()
+		labels := make([]interface{}, len(s.Labels))
+		for x, c := range s.Labels {
+			labels[x] = labelCountsValue(c)
+		}
+		return map[string]interface{}{
+			"weight":          jsonFloat(s.Weight),
+			"hamming_loss":    jsonFloat(s.HammingLoss),
+			"subset_accuracy": jsonFloat(s.SubsetAccuracy),
+			"jaccard":         jsonFloat(s.Jaccard),
+			"micro_f1":        jsonFloat(s.MicroF1),
+			"macro_f1":        jsonFloat(s.MacroF1),
+			"labels":          labels,
+		}
+	case *Fairness:
+		s := m.Snapshot()
+		groups := make(map[string]interface{}, len(s.Groups))
+		for g, c := range s.Groups {
+			groups[g] = labelCountsValue(c)
+		}
+		return map[string]interface{}{
+			"weight":                        jsonFloat(s.Weight),
+			"groups":                        groups,
+			"demographic_parity_difference": jsonFloat(s.DemographicParityDifference),
+			"demographic_parity_ratio":      jsonFloat(s.DemographicParityRatio),
+			"equal_opportunity_difference":  jsonFloat(s.EqualOpportunityDifference),
+			"equalized_odds_difference":     jsonFloat(s.EqualizedOddsDifference),
+			"predictive_parity_difference":  jsonFloat(s.PredictiveParityDifference),
+		}
+	case *Ranking:
+		s := m.Snapshot()
+		return map[string]interface{}{
+			"k":         s.K,
+			"weight":    jsonFloat(s.Weight),
+			"ndcg":      jsonFloat(s.NDCG),
+			"map":       jsonFloat(s.MAP),
+			"mrr":       jsonFloat(s.MRR),
+			"precision": jsonFloat(s.Precision),
+			"recall":    jsonFloat(s.Recall),
+			"hit_rate":  jsonFloat(s.HitRate),
+		}
+	case *Recommendations:
+		s := m.Snapshot()
+		return map[string]interface{}{
+			"weight":          jsonFloat(s.Weight),
+			"num_items":       s.NumItems,
+			"coverage":        jsonFloat(s.Coverage),
+			"novelty":         jsonFloat(s.Novelty),
+			"diversity":       jsonFloat(s.Diversity),
+			"personalization": jsonFloat(s.Personalization),
+		}
+	case windowedMetric:
+		views := m.windowViews()
+		res := make([]handlerWindow, len(views))
+		for i, v := range views {
+			res[i] = handlerWindow{Start: v.Start, End: v.End, Values: handlerValue(v.Metric)}
+		}
+		return res
+	case segmentedMetric:
+		res := m.segmentViews()
+		for key, v := range res {
+			res[key] = handlerValue(v)
+		}
+		return res
+	}
+	return nil
+}
+
+func regressionValue(s RegressionSnapshot) interface{} {
+	return map[string]interface{}{
+		"weight":    jsonFloat(s.Weight),
+		"mean":      jsonFloat(s.Mean),
+		"mae":       jsonFloat(s.MAE),
+		"mse":       jsonFloat(s.MSE),
+		"rmse":      jsonFloat(s.RMSE),
+		"msle":      jsonFloat(s.MSLE),
+		"rmsle":     jsonFloat(s.RMSLE),
+		"r2":        jsonFloat(s.R2),
+		"max_error": jsonFloat(s.MaxError),
+	}
+}
+
+func labelCountsValue(c LabelCounts) interface{} {
+	return map[string]interface{}{
+		"tp": jsonFloat(c.TP),
+		"fp": jsonFloat(c.FP),
+		"fn": jsonFloat(c.FN),
+		"tn": jsonFloat(c.TN),
+	}
+}
+
+func confusionValue(s ConfusionMatrixSnapshot) interface{} {
+	classes := make([]map[string]interface{}, len(s.Classes))
+	for x, c := range s.Classes {
+		classes[x] = map[string]interface{}{
+			"precision":   jsonFloat(c.Precision),
+			"sensitivity": jsonFloat(c.Sensitivity),
+			"f1":          jsonFloat(c.F1),
+		}
+	}
+
+	// each row contains the weights of the predicted categories for an actual category
+	return map[string]interface{}{
+		"weight":   jsonFloat(s.Weight),
+		"accuracy": jsonFloat(s.Accuracy),
+		"kappa":    jsonFloat(s.Kappa),
+		"matthews": jsonFloat(s.Matthews),
+		"matrix":   jsonFloats(s.Matrix),
+		"classes":  classes,
+	}
+}
+
+// jsonFloats converts the rows of a matrix, see jsonFloat.
+func jsonFloats(rows [][]float64) [][]interface{} {
+	res := make([][]interface{}, len(rows))
+	for i, row := range rows {
+		res[i] = make([]interface{}, len(row))
+		for j, v := range row {
+			res[i][j] = jsonFloat(v)
+		}
+	}
+	return res
+}
+
+// jsonFloat converts non-finite values, which cannot be encoded as JSON, to nil.
+func jsonFloat(v float64) interface{} {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil
+	}
+	return v
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = buf.WriteTo(w)
+}
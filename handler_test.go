@@ -0,0 +1,193 @@
+package mlmetrics_test
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/bsm/ginkgo"
+	. "github.com/bsm/gomega"
+	"github.com/bsm/mlmetrics"
+)
+
+var _ = Describe("Handler", func() {
+	var subject *mlmetrics.Handler
+	var accuracy *mlmetrics.Accuracy
+
+	epoch := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		subject.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	decode := func(w *httptest.ResponseRecorder) interface{} {
+		var v interface{}
+		Expect(json.Unmarshal(w.Body.Bytes(), &v)).To(Succeed())
+		return v
+	}
+
+	BeforeEach(func() {
+		accuracy = mlmetrics.NewAccuracy()
+		accuracy.Observe(1, 1)
+		accuracy.Observe(1, 0)
+
+		confusion := mlmetrics.NewConfusionMatrix()
+		confusion.Observe(0, 0)
+		confusion.Observe(1, 0)
+		confusion.Observe(1, 1)
+
+		regression := mlmetrics.NewRegression()
+		regression.Observe(1, 2)
+
+		windowed := mlmetrics.NewWindowed(mlmetrics.NewLogLoss, time.Hour, 2)
+		windowed.At(epoch).Observe(0.5)
+
+		segmented := mlmetrics.NewSegmented(mlmetrics.NewAccuracy)
+		segmented.In("de").Observe(1, 1)
+
+		subject = mlmetrics.NewHandler(&mlmetrics.HandlerOptions{AllowReset: true})
+		subject.Register("accuracy", accuracy)
+		subject.Register("confusion", confusion)
+		subject.Register("regression", regression)
+		subject.Register("windowed", windowed)
+		subject.Register("segmented", segmented)
+		subject.RegisterFunc("custom", func() interface{} { return "ok" })
+	})
+
+	It("should serve all metrics", func() {
+		w := serve("GET", "/")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Content-Type")).To(Equal("application/json"))
+		Expect(decode(w)).To(Equal(map[string]interface{}{
+			"accuracy": map[string]interface{}{"weight": 2.0, "correct": 1.0, "rate": 0.5},
+			"confusion": map[string]interface{}{
+				"weight":   3.0,
+				"accuracy": 2.0 / 3.0,
+				"kappa":    0.4,
+				"matthews": 0.5,
+				"matrix":   []interface{}{[]interface{}{1.0, 0.0}, []interface{}{1.0, 1.0}},
+				"classes": []interface{}{
+					map[string]interface{}{"precision": 0.5, "sensitivity": 1.0, "f1": 2.0 / 3.0},
+					map[string]interface{}{"precision": 1.0, "sensitivity": 0.5, "f1": 2.0 / 3.0},
+				},
+			},
+			"regression": map[string]interface{}{
+				"weight": 1.0, "mean": 1.0, "mae": 1.0, "mse": 1.0, "rmse": 1.0,
+				"msle": 0.16440195389316534, "rmsle": 0.4054651081081643, "r2": 0.0, "max_error": 1.0,
+			},
+			"windowed": []interface{}{
+				map[string]interface{}{
+					"start":  "2020-01-01T00:00:00Z",
+					"end":    "2020-01-01T01:00:00Z",
+					"values": map[string]interface{}{"score": 0.6931471805599453},
+				},
+			},
+			"segmented": map[string]interface{}{
+				"de": map[string]interface{}{"weight": 1.0, "correct": 1.0, "rate": 1.0},
+			},
+			"custom": "ok",
+		}))
+	})
+
+	It("should serve individual metrics", func() {
+		w := serve("GET", "/accuracy")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(decode(w)).To(Equal(map[string]interface{}{"weight": 2.0, "correct": 1.0, "rate": 0.5}))
+
+		Expect(serve("GET", "/unknown").Code).To(Equal(http.StatusNotFound))
+
+		subject.Unregister("accuracy")
+		Expect(serve("GET", "/accuracy").Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("should serve all metrics with snapshots", func() {
+		subject = mlmetrics.NewHandler(nil)
+		subject.Register("clustering", mlmetrics.NewClustering())
+		subject.Register("correlation", mlmetrics.NewCorrelation())
+		subject.Register("deviance", mlmetrics.NewPoissonDeviance())
+		subject.Register("huber", mlmetrics.NewHuberLoss(1))
+		subject.Register("fairness", mlmetrics.NewFairness())
+		subject.Register("gains", mlmetrics.NewGains())
+		subject.Register("multilabel", mlmetrics.NewMultiLabel())
+		subject.Register("multiregression", mlmetrics.NewMultiRegression())
+		subject.Register("quantile", mlmetrics.NewQuantileLoss())
+		subject.Register("interval", mlmetrics.NewIntervalCoverage(0.2))
+		subject.Register("ranking", mlmetrics.NewRanking(3))
+		subject.Register("recommendations", mlmetrics.NewRecommendations(nil))
+
+		w := serve("GET", "/")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		values := decode(w).(map[string]interface{})
+		Expect(values).To(HaveLen(12))
+		for name, v := range values {
+			Expect(v).To(BeAssignableToTypeOf(map[string]interface{}{}), "metric %q", name)
+		}
+
+		w = serve("GET", "/gains")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(decode(w)).To(Equal(map[string]interface{}{"weight": 0.0, "ks": 0.0, "auc": 0.0, "gini": 0.0}))
+	})
+
+	It("should encode non-finite values as null", func() {
+		accuracy.ObserveWeight(1, 1, math.Inf(1))
+		w := serve("GET", "/")
+		Expect(w.Code).To(Equal(http.StatusOK))
+
+		values := decode(w).(map[string]interface{})
+		Expect(values).To(HaveLen(6))
+		Expect(values["accuracy"]).To(Equal(map[string]interface{}{"weight": nil, "correct": nil, "rate": nil}))
+	})
+
+	It("should fail on values which cannot be encoded", func() {
+		subject.RegisterFunc("custom", func() interface{} { return math.NaN() })
+		w := serve("GET", "/custom")
+		Expect(w.Code).To(Equal(http.StatusInternalServerError))
+		Expect(w.Header().Get("Content-Type")).NotTo(Equal("application/json"))
+	})
+
+	It("should reject unsupported metrics", func() {
+		Expect(func() { subject.Register("struct", struct{}{}) }).To(PanicWith(ContainSubstring("struct {}")))
+		Expect(func() { subject.Register("join", mlmetrics.NewJoin(time.Hour)) }).To(Panic())
+		Expect(func() {
+			subject.Register("windowed", mlmetrics.NewWindowed(func() *mlmetrics.Join { return nil }, time.Hour, 1))
+		}).To(Panic())
+		Expect(func() {
+			subject.Register("nested", mlmetrics.NewSegmented(func() *mlmetrics.Windowed[*mlmetrics.Gains] { return nil }))
+		}).NotTo(Panic())
+		Expect(serve("GET", "/struct").Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("should check methods", func() {
+		w := serve("POST", "/accuracy")
+		Expect(w.Code).To(Equal(http.StatusMethodNotAllowed))
+		Expect(w.Header().Get("Allow")).To(Equal("GET, HEAD"))
+
+		w = serve("GET", "/accuracy/reset")
+		Expect(w.Code).To(Equal(http.StatusMethodNotAllowed))
+		Expect(w.Header().Get("Allow")).To(Equal("POST"))
+		Expect(accuracy.TotalWeight()).To(Equal(2.0))
+	})
+
+	It("should reset metrics", func() {
+		Expect(serve("POST", "/accuracy/reset").Code).To(Equal(http.StatusNoContent))
+		Expect(accuracy.TotalWeight()).To(Equal(0.0))
+
+		Expect(serve("POST", "/unknown/reset").Code).To(Equal(http.StatusNotFound))
+		Expect(serve("POST", "/custom/reset").Code).To(Equal(http.StatusUnprocessableEntity))
+
+		Expect(serve("POST", "/reset").Code).To(Equal(http.StatusNoContent))
+		Expect(decode(serve("GET", "/windowed"))).To(BeEmpty())
+	})
+
+	It("should disable resets by default", func() {
+		subject = mlmetrics.NewHandler(nil)
+		subject.Register("accuracy", accuracy)
+		Expect(serve("POST", "/accuracy/reset").Code).To(Equal(http.StatusNotFound))
+		Expect(serve("POST", "/reset").Code).To(Equal(http.StatusNotFound))
+		Expect(accuracy.TotalWeight()).To(Equal(2.0))
+	})
+})
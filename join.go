@@ -0,0 +1,245 @@
+package mlmetrics
+
+import (
+	"container/heap"
+	"math"
+	"sync"
+	"time"
+)
+
+// Prediction is a prediction recorded by a Join. Depending on the attached metrics,
+// only some of the fields need to be set.
+type Prediction struct {
+	Category int       // predicted category, used by Accuracy and ConfusionMatrix
+	Value    float64   // predicted value, used by Regression; probability of the positive class for binary LogLoss
+	Probs    []float64 // predicted probabilities of each category, used by LogLoss
+	Weight   float64   // observation weight, defaults to 1
+}
+
+// JoinFunc is called for every prediction that was matched with its actual outcome.
+type JoinFunc func(pred Prediction, actual float64)
+
+// Join records predictions until their actual outcomes become known and forwards
+// matched pairs to attached metrics. Predictions that remain unresolved for longer
+// than the TTL are discarded. Categorical outcomes are passed as float64 values and
+// must be whole numbers.
+type Join struct {
+	ttl     time.Duration
+	pending map[string]*joinEntry
+	queue   joinQueue // ordered by expiry
+	targets []JoinFunc
+
+	matched, expired, unmatched int
+
+	mu sync.Mutex
+}
+
+// NewJoin inits a new join with a TTL. Default: 24h.
+func NewJoin(ttl time.Duration) *Join {
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	return &Join{ttl: ttl, pending: make(map[string]*joinEntry)}
+}
+
+// AttachFunc attaches a custom callback.
+func (j *Join) AttachFunc(fn JoinFunc) {
+	j.mu.Lock()
+	j.targets = append(j.targets, fn)
+	j.mu.Unlock()
+}
+
+// AttachAccuracy attaches an Accuracy metric.
+func (j *Join) AttachAccuracy(m *Accuracy) {
+	j.AttachFunc(func(pred Prediction, actual float64) {
+		m.ObserveWeight(joinCategory(actual), pred.Category, pred.Weight)
+	})
+}
+
+// AttachConfusionMatrix attaches a ConfusionMatrix metric.
+func (j *Join) AttachConfusionMatrix(m *ConfusionMatrix) {
+	j.AttachFunc(func(pred Prediction, actual float64) {
+		m.ObserveWeight(joinCategory(actual), pred.Category, pred.Weight)
+	})
+}
+
+// AttachRegression attaches a Regression metric.
+func (j *Join) AttachRegression(m *Regression) {
+	j.AttachFunc(func(pred Prediction, actual float64) {
+		m.ObserveWeight(actual, pred.Value, pred.Weight)
+	})
+}
+
+// AttachLogLoss attaches a LogLoss metric. The probability of the actual category is
+// taken from Probs. If Probs is empty, Value is interpreted as the probability of the
+// positive class of a binary classifier, with actual outcomes of 0 or 1.
+func (j *Join) AttachLogLoss(m *LogLoss) {
+	j.AttachFunc(func(pred Prediction, actual float64) {
+		x := joinCategory(actual)
+		if len(pred.Probs) != 0 {
+			if x > -1 && x < len(pred.Probs) {
+				m.ObserveWeight(pred.Probs[x], pred.Weight)
+			}
+			return
+		}
+
+		switch x {
+		case 0:
+			m.ObserveWeight(1-pred.Value, pred.Weight)
+		case 1:
+			m.ObserveWeight(pred.Value, pred.Weight)
+		}
+	})
+}
+
+// Record records a prediction by ID. Recording a prediction with an ID that is
+// already pending replaces it.
+func (j *Join) Record(id string, pred Prediction) {
+	j.RecordAt(id, pred, time.Now())
+}
+
+// RecordAt records a prediction by ID made at time t.
+func (j *Join) RecordAt(id string, pred Prediction, t time.Time) {
+	if pred.Weight == 0 {
+		pred.Weight = 1.0
+	}
+	if !isValidWeight(pred.Weight) {
+		return
+	}
+
+	expires := t.Add(j.ttl)
+
+	j.mu.Lock()
+	j.expire(t)
+	if entry, ok := j.pending[id]; ok {
+		entry.pred, entry.expires = pred, expires
+		heap.Fix(&j.queue, entry.index)
+	} else {
+		entry := &joinEntry{id: id, pred: pred, expires: expires}
+		heap.Push(&j.queue, entry)
+		j.pending[id] = entry
+	}
+	j.mu.Unlock()
+}
+
+// Resolve resolves a pending prediction by ID with the actual outcome and forwards
+// the pair to all attached metrics. Returns false if no (unexpired) prediction was
+// found.
+func (j *Join) Resolve(id string, actual float64) bool {
+	return j.ResolveAt(id, actual, time.Now())
+}
+
+// ResolveAt resolves a pending prediction by ID at time t.
+func (j *Join) ResolveAt(id string, actual float64, t time.Time) bool {
+	j.mu.Lock()
+	j.expire(t)
+	entry, ok := j.pending[id]
+	if ok {
+		delete(j.pending, id)
+		heap.Remove(&j.queue, entry.index)
+		j.matched++
+	} else {
+		j.unmatched++
+	}
+	targets := j.targets
+	j.mu.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	for _, fn := range targets {
+		fn(entry.pred, actual)
+	}
+	return true
+}
+
+// Expire discards all predictions that expired before t. Expired predictions are
+// also discarded on every call to Record and Resolve. Returns the number of discarded
+// predictions.
+func (j *Join) Expire(t time.Time) int {
+	j.mu.Lock()
+	n := j.expire(t)
+	j.mu.Unlock()
+	return n
+}
+
+// Len returns the number of pending predictions.
+func (j *Join) Len() int {
+	j.mu.Lock()
+	n := len(j.pending)
+	j.mu.Unlock()
+	return n
+}
+
+// Matched returns the number of resolved predictions.
+func (j *Join) Matched() int {
+	j.mu.Lock()
+	n := j.matched
+	j.mu.Unlock()
+	return n
+}
+
+// Expired returns the number of predictions that expired before they were resolved.
+func (j *Join) Expired() int {
+	j.mu.Lock()
+	n := j.expired
+	j.mu.Unlock()
+	return n
+}
+
+// Unmatched returns the number of outcomes for which no pending prediction was found.
+func (j *Join) Unmatched() int {
+	j.mu.Lock()
+	n := j.unmatched
+	j.mu.Unlock()
+	return n
+}
+
+func (j *Join) expire(t time.Time) (n int) {
+	for len(j.queue) != 0 && !j.queue[0].expires.After(t) {
+		entry := heap.Pop(&j.queue).(*joinEntry)
+		delete(j.pending, entry.id)
+		n++
+	}
+	j.expired += n
+	return
+}
+
+// --------------------------------------------------------------------
+
+type joinEntry struct {
+	id      string
+	pred    Prediction
+	expires time.Time
+	index   int // position in the queue
+}
+
+type joinQueue []*joinEntry
+
+func (q joinQueue) Len() int           { return len(q) }
+func (q joinQueue) Less(i, j int) bool { return q[i].expires.Before(q[j].expires) }
+func (q joinQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index, q[j].index = i, j
+}
+func (q *joinQueue) Push(x interface{}) {
+	entry := x.(*joinEntry)
+	entry.index = len(*q)
+	*q = append(*q, entry)
+}
+func (q *joinQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	x := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return x
+}
+
+func joinCategory(actual float64) int {
+	if actual < 0 || actual != math.Trunc(actual) || actual > math.MaxInt32 {
+		return -1
+	}
+	return int(actual)
+}
@@ -0,0 +1,110 @@
+package mlmetrics_test
+
+import (
+	"time"
+
+	. "github.com/bsm/ginkgo"
+	. "github.com/bsm/gomega"
+	"github.com/bsm/mlmetrics"
+)
+
+var _ = Describe("Join", func() {
+	var subject *mlmetrics.Join
+	var accuracy *mlmetrics.Accuracy
+	var confusion *mlmetrics.ConfusionMatrix
+	var logLoss *mlmetrics.LogLoss
+
+	epoch := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	BeforeEach(func() {
+		accuracy = mlmetrics.NewAccuracy()
+		confusion = mlmetrics.NewConfusionMatrix()
+		logLoss = mlmetrics.NewLogLoss()
+
+		subject = mlmetrics.NewJoin(time.Hour)
+		subject.AttachAccuracy(accuracy)
+		subject.AttachConfusionMatrix(confusion)
+		subject.AttachLogLoss(logLoss)
+
+		subject.RecordAt("a", mlmetrics.Prediction{Category: 1, Probs: []float64{0.2, 0.8}}, epoch)
+		subject.RecordAt("b", mlmetrics.Prediction{Category: 0, Probs: []float64{0.6, 0.4}}, epoch.Add(time.Minute))
+		subject.RecordAt("c", mlmetrics.Prediction{Category: 1, Probs: []float64{0.1, 0.9}, Weight: 2}, epoch.Add(2*time.Minute))
+	})
+
+	It("should forward matched pairs", func() {
+		Expect(subject.Len()).To(Equal(3))
+		Expect(subject.ResolveAt("a", 1, epoch.Add(10*time.Minute))).To(BeTrue())
+		Expect(subject.ResolveAt("c", 0, epoch.Add(10*time.Minute))).To(BeTrue())
+		Expect(subject.ResolveAt("a", 1, epoch.Add(10*time.Minute))).To(BeFalse())
+		Expect(subject.ResolveAt("x", 1, epoch.Add(10*time.Minute))).To(BeFalse())
+
+		Expect(subject.Len()).To(Equal(1))
+		Expect(subject.Matched()).To(Equal(2))
+		Expect(subject.Unmatched()).To(Equal(2))
+
+		Expect(accuracy.TotalWeight()).To(Equal(3.0))
+		Expect(accuracy.Rate()).To(BeNumerically("~", 0.333, 0.001))
+		Expect(confusion.Row(0)).To(Equal([]float64{0, 2}))
+		Expect(logLoss.Score()).To(BeNumerically("~", 1.609, 0.001))
+	})
+
+	It("should expire predictions", func() {
+		Expect(subject.Expire(epoch.Add(time.Hour))).To(Equal(1))
+		Expect(subject.ResolveAt("a", 1, epoch.Add(time.Hour))).To(BeFalse())
+		Expect(subject.ResolveAt("b", 0, epoch.Add(time.Hour+time.Minute))).To(BeFalse())
+		Expect(subject.ResolveAt("c", 1, epoch.Add(time.Hour+time.Minute))).To(BeTrue())
+
+		Expect(subject.Len()).To(Equal(0))
+		Expect(subject.Expired()).To(Equal(2))
+		Expect(subject.Matched()).To(Equal(1))
+		Expect(accuracy.TotalWeight()).To(Equal(2.0))
+	})
+
+	It("should replace predictions", func() {
+		subject.RecordAt("a", mlmetrics.Prediction{Category: 0}, epoch.Add(30*time.Minute))
+		Expect(subject.Expire(epoch.Add(time.Hour + 5*time.Minute))).To(Equal(2))
+		Expect(subject.Len()).To(Equal(1))
+
+		Expect(subject.ResolveAt("a", 0, epoch.Add(time.Hour+5*time.Minute))).To(BeTrue())
+		Expect(accuracy.Rate()).To(Equal(1.0))
+	})
+
+	It("should expire predictions recorded out of order", func() {
+		subject.RecordAt("d", mlmetrics.Prediction{Category: 1}, epoch.Add(-30*time.Minute))
+		Expect(subject.Expire(epoch.Add(30 * time.Minute))).To(Equal(1))
+		Expect(subject.Len()).To(Equal(3))
+
+		Expect(subject.ResolveAt("b", 0, epoch.Add(30*time.Minute))).To(BeTrue())
+		Expect(subject.Expire(epoch.Add(time.Hour + time.Minute))).To(Equal(1))
+		Expect(subject.Len()).To(Equal(1))
+		Expect(subject.Expired()).To(Equal(2))
+	})
+
+	It("should forward to regression and binary log-loss", func() {
+		regression := mlmetrics.NewRegression()
+		binary := mlmetrics.NewLogLoss()
+
+		subject = mlmetrics.NewJoin(0)
+		subject.AttachRegression(regression)
+		subject.AttachLogLoss(binary)
+
+		subject.Record("a", mlmetrics.Prediction{Value: 0.8})
+		subject.Record("b", mlmetrics.Prediction{Value: 0.3})
+		Expect(subject.Resolve("a", 1)).To(BeTrue())
+		Expect(subject.Resolve("b", 0)).To(BeTrue())
+
+		Expect(regression.TotalWeight()).To(Equal(2.0))
+		Expect(regression.MAE()).To(BeNumerically("~", 0.25, 0.001))
+		Expect(binary.Score()).To(BeNumerically("~", 0.290, 0.001))
+	})
+
+	It("should ignore invalid inputs", func() {
+		subject.RecordAt("x", mlmetrics.Prediction{Weight: -1}, epoch)
+		Expect(subject.Len()).To(Equal(3))
+
+		Expect(subject.ResolveAt("a", 0.5, epoch)).To(BeTrue())
+		Expect(subject.ResolveAt("b", -1, epoch)).To(BeTrue())
+		Expect(accuracy.TotalWeight()).To(Equal(0.0))
+		Expect(logLoss.Score()).To(Equal(mlmetrics.NewLogLoss().Score()))
+	})
+})
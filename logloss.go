@@ -2,16 +2,20 @@ package mlmetrics
 
 import (
 	"math"
-	"sync"
 )
 
 // LogLoss, aka logistic loss or cross-entropy loss.
 type LogLoss struct {
+	rejector
 	epsilon float64
-	logsum  float64
-	weight  float64
+	shards  shards[logLossState]
+}
 
-	mu sync.RWMutex
+// LogLossSnapshot is a point-in-time snapshot of a LogLoss metric.
+type LogLossSnapshot struct {
+	Weight   float64    // total weight observed
+	Score    float64    // logarithmic loss
+	Rejected Rejections // observations rejected by validation
 }
 
 // NewLogLoss inits a log-loss metric.
@@ -30,10 +34,8 @@ func NewLogLossWithEpsilon(epsilon float64) *LogLoss {
 
 // Reset resets state.
 func (m *LogLoss) Reset() {
-	m.mu.Lock()
-	m.logsum = 0
-	m.weight = 0
-	m.mu.Unlock()
+	m.shards.each(func(s *logLossState) { *s = logLossState{} })
+	m.resetRejected()
 }
 
 // Observe records the predicted probability of the actually observed value.
@@ -55,29 +57,124 @@ func (m *LogLoss) Observe(prob float64) {
 
 // ObserveWeight records an observation with a given weight.
 func (m *LogLoss) ObserveWeight(prob float64, weight float64) {
-	if !isValidProbability(prob) || !isValidWeight(weight) {
-		return
+	_ = m.ObserveWeightStrict(prob, weight)
+}
+
+// ObserveWeightStrict is like ObserveWeight, but returns an error if the observation was
+// rejected by validation, see Rejected.
+func (m *LogLoss) ObserveWeightStrict(prob float64, weight float64) error {
+	if err := validateLogLoss(prob, weight); err != nil {
+		return m.reject(err)
 	}
 
-	if prob == 0 {
-		prob += m.epsilon
+	logprob := m.logProb(prob)
+
+	sh := m.shards.acquire()
+	sh.state.weight += weight
+	sh.state.logsum += weight * logprob
+	m.shards.release(sh)
+	return nil
+}
+
+// ObserveBatch records a batch of predicted probabilities of the actually observed values.
+// Weights are optional, if given, they must match the number of observations.
+// Returns ErrLengthMismatch if the lengths of the inputs differ and a *BatchError
+// if observations were rejected.
+func (m *LogLoss) ObserveBatch(probs []float64, weights []float64) error {
+	return ObserveLogLossBatch(m, probs, weights)
+}
+
+// ObserveLogLossBatch records a batch of predicted probabilities of any floating-point
+// type, e.g. float32 model outputs, without converting the inputs first.
+// See LogLoss.ObserveBatch.
+func ObserveLogLossBatch[P Float](m *LogLoss, probs []P, weights []float64) error {
+	if err := validateBatch(len(probs), weights); err != nil {
+		return m.rejectBatch(len(probs), err)
 	}
 
-	m.mu.Lock()
-	m.weight += weight
-	m.logsum += weight * math.Log(prob)
-	m.mu.Unlock()
+	var berr BatchError
+	sh := m.shards.acquire()
+	for i := range probs {
+		prob, weight := float64(probs[i]), batchWeight(weights, i)
+		if err := validateLogLoss(prob, weight); err != nil {
+			berr.add(i, m.reject(err))
+			continue
+		}
+		sh.state.weight += weight
+		sh.state.logsum += weight * m.logProb(prob)
+	}
+	m.shards.release(sh)
+	return berr.errOrNil()
 }
 
 // Score calculates the logarithmic loss.
 func (m *LogLoss) Score() float64 {
-	m.mu.RLock()
-	logsum := m.logsum
-	weight := m.weight
-	m.mu.RUnlock()
+	return m.score(m.merged())
+}
+
+// Snapshot returns a consistent point-in-time snapshot of all values.
+func (m *LogLoss) Snapshot() LogLossSnapshot {
+	snap := m.snapshot(m.merged())
+	snap.Rejected = m.Rejected()
+	return snap
+}
+
+// SnapshotAndReset atomically returns a snapshot of all values and resets state.
+func (m *LogLoss) SnapshotAndReset() LogLossSnapshot {
+	snap := m.snapshot(m.harvest())
+	snap.Rejected = m.harvestRejected()
+	return snap
+}
+
+// merged returns the merged state of all shards.
+func (m *LogLoss) merged() *logLossState {
+	st := new(logLossState)
+	m.shards.each(st.merge)
+	return st
+}
+
+// harvest returns the merged state of all shards and resets them.
+func (m *LogLoss) harvest() *logLossState {
+	st := new(logLossState)
+	m.shards.each(func(s *logLossState) {
+		st.merge(s)
+		*s = logLossState{}
+	})
+	return st
+}
 
-	if weight > 0 {
-		return -logsum / weight
+func (m *LogLoss) logProb(prob float64) float64 {
+	if prob == 0 {
+		prob += m.epsilon
+	}
+	return math.Log(prob)
+}
+
+func (m *LogLoss) score(st *logLossState) float64 {
+	if st.weight > 0 {
+		return -st.logsum / st.weight
 	}
 	return -math.Log(m.epsilon)
 }
+
+func (m *LogLoss) snapshot(st *logLossState) LogLossSnapshot {
+	return LogLossSnapshot{Weight: st.weight, Score: m.score(st)}
+}
+
+type logLossState struct {
+	logsum float64
+	weight float64
+}
+
+func (s *logLossState) merge(o *logLossState) {
+	s.logsum += o.logsum
+	s.weight += o.weight
+}
+
+// validateLogLoss validates an observation of a probability.
+func validateLogLoss(prob, weight float64) error {
+	if !isValidProbability(prob) {
+		return ErrInvalidProbability
+	}
+	return validateWeight(weight)
+}
@@ -2,6 +2,7 @@ package mlmetrics_test
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
 	"testing"
 
@@ -60,6 +61,49 @@ var _ = Describe("LogLoss", func() {
 		subject.ObserveWeight(0.0, 10)
 		Expect(subject.Score()).To(BeNumerically("~", 34.539, 0.001))
 	})
+
+	It("should merge concurrent observations", func() {
+		expected := mlmetrics.NewLogLoss()
+		for i := 0; i < 1000; i++ {
+			expected.Observe(float64(i%100) / 100)
+		}
+
+		observeConcurrently(1000, func(i int) {
+			subject.Observe(float64(i%100) / 100)
+		})
+		Expect(subject.Snapshot().Weight).To(Equal(1000.0))
+		Expect(subject.Score()).To(BeNumerically("~", expected.Score(), 1e-9))
+	})
+
+	It("should observe batches", func() {
+		expected := mlmetrics.NewLogLoss()
+		expected.Observe(0.5)
+		expected.Observe(0)
+		expected.ObserveWeight(0.9, 2)
+
+		Expect(subject.ObserveBatch([]float64{0.5, 0, 0.9, 1.5}, []float64{1, 1, 2, 1})).To(MatchError(mlmetrics.ErrInvalidProbability))
+		Expect(subject.Snapshot()).To(equalSnapshot(expected.Snapshot()))
+		Expect(subject.ObserveBatch([]float64{0.5}, []float64{})).To(MatchError(mlmetrics.ErrLengthMismatch))
+	})
+
+	It("should observe float32 batches", func() {
+		expected := mlmetrics.NewLogLoss()
+		Expect(expected.ObserveBatch([]float64{float64(float32(0.5)), 0, float64(float32(0.9))}, []float64{1, 1, 2})).To(Succeed())
+
+		Expect(mlmetrics.ObserveLogLossBatch(subject, []float32{0.5, 0, 0.9, 1.5}, []float64{1, 1, 2, 1})).To(MatchError(mlmetrics.ErrInvalidProbability))
+		Expect(subject.Snapshot()).To(equalSnapshot(expected.Snapshot()))
+		Expect(subject.Rejected()).To(Equal(mlmetrics.Rejections{InvalidProbability: 1}))
+	})
+
+	It("should reject invalid probabilities", func() {
+		subject.Observe(1.5)
+		subject.Observe(-0.1)
+		Expect(subject.Rejected()).To(Equal(mlmetrics.Rejections{InvalidProbability: 2}))
+
+		Expect(subject.ObserveWeightStrict(1.5, 1)).To(MatchError(mlmetrics.ErrInvalidProbability))
+		Expect(subject.ObserveWeightStrict(0.5, math.NaN())).To(MatchError(mlmetrics.ErrInvalidWeight))
+		Expect(subject.Rejected()).To(Equal(mlmetrics.Rejections{InvalidProbability: 3, InvalidWeight: 1}))
+	})
 })
 
 func ExampleLogLoss() {
@@ -99,3 +143,13 @@ func BenchmarkLogLoss(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkLogLoss_ObserveParallel(b *testing.B) {
+	ll := mlmetrics.NewLogLoss()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			ll.Observe(float64(i%100) / 100)
+		}
+	})
+}
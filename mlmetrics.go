@@ -1,6 +1,26 @@
 package mlmetrics
 
-import "math"
+import (
+	"errors"
+	"math"
+)
+
+// ErrLengthMismatch is returned when inputs have mismatching lengths.
+var ErrLengthMismatch = errors.New("mlmetrics: length mismatch")
+
+// Float is a constraint that permits any floating-point type.
+type Float interface {
+	~float32 | ~float64
+}
+
+// Integer is a constraint that permits any integer type.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Metrics guarded by a single mutex implement the unexported helpers observe, clone
+// and reset, which require the caller to hold the lock, and snapshot, which is only
+// called on an unshared clone, so metrics are calculated outside of the lock.
 
 func maxInt(n, m int) int {
 	if n > m {
@@ -9,7 +29,54 @@ func maxInt(n, m int) int {
 	return m
 }
 
+func uniqueFloat64s(vv []float64) []float64 {
+	if len(vv) == 0 {
+		return vv
+	}
+
+	n := 1
+	for _, v := range vv[1:] {
+		if v != vv[n-1] {
+			vv[n] = v
+			n++
+		}
+	}
+	return vv[:n]
+}
+
+// validateBatch returns ErrLengthMismatch unless all inputs of a batch have the
+// same number of observations n. Weights are optional and may be nil.
+func validateBatch(n int, weights []float64, lengths ...int) error {
+	if weights != nil && len(weights) != n {
+		return ErrLengthMismatch
+	}
+	for _, l := range lengths {
+		if l != n {
+			return ErrLengthMismatch
+		}
+	}
+	return nil
+}
+
+// batchWeight returns the weight of observation i of a batch.
+func batchWeight(weights []float64, i int) float64 {
+	if weights == nil {
+		return 1.0
+	}
+	return weights[i]
+}
+
+// categoryOf converts x to a category, returns -1 if x cannot be represented
+// as a non-negative int.
+func categoryOf[T Integer](x T) int {
+	if c := int(x); c > -1 && T(c) == x {
+		return c
+	}
+	return -1
+}
+
 func isValidProbability(p float64) bool { return p >= 0 && p <= 1 }
 func isValidWeight(w float64) bool      { return w > 0 }
 func isValidCategory(x int) bool        { return x > -1 }
 func isValidNumeric(v float64) bool     { return !math.IsNaN(v) }
+func isFinite(v float64) bool           { return !math.IsNaN(v) && !math.IsInf(v, 0) }
@@ -1,10 +1,14 @@
 package mlmetrics_test
 
 import (
+	"reflect"
+	"runtime"
+	"sync"
 	"testing"
 
 	. "github.com/bsm/ginkgo"
 	. "github.com/bsm/gomega"
+	"github.com/bsm/gomega/types"
 )
 
 func TestSuite(t *testing.T) {
@@ -28,3 +32,56 @@ func intVector(slices ...[]int) (nn []int) {
 	}
 	return nn
 }
+
+// equalSnapshot succeeds if a snapshot equals the expected snapshot, regardless of
+// the rejected observations.
+func equalSnapshot(expected interface{}) types.GomegaMatcher {
+	return WithTransform(withoutRejections, Equal(withoutRejections(expected)))
+}
+
+func withoutRejections(snap interface{}) interface{} {
+	v := reflect.New(reflect.TypeOf(snap)).Elem()
+	v.Set(reflect.ValueOf(snap))
+	field := v.FieldByName("Rejected")
+	field.Set(reflect.Zero(field.Type()))
+	return v.Interface()
+}
+
+// observeConcurrently calls fn for each i in [0, n) from multiple goroutines,
+// running on multiple processors.
+func observeConcurrently(n int, fn func(i int)) {
+	const procs = 4
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+
+	var wg sync.WaitGroup
+	for p := 0; p < procs; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := p; i < n; i += procs {
+				fn(i)
+			}
+		}(p)
+	}
+	wg.Wait()
+}
+
+// harvestConcurrently calls observe for each i in [0, n), see observeConcurrently,
+// while harvest is called repeatedly. It returns the sum of all harvested weights.
+func harvestConcurrently(n int, observe func(i int), harvest func() float64) float64 {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		observeConcurrently(n, observe)
+	}()
+
+	var total float64
+	for {
+		select {
+		case <-done:
+			return total + harvest()
+		default:
+			total += harvest()
+		}
+	}
+}
@@ -0,0 +1,390 @@
+package mlmetrics
+
+import (
+	"sync"
+)
+
+// LabelCounts contains the (weighted) binary confusion counts of a single label.
+type LabelCounts struct {
+	TP float64 // true positives
+	FP float64 // false positives
+	FN float64 // false negatives
+	TN float64 // true negatives
+}
+
+// MultiLabel evaluates multilabel classifiers, which predict a set of labels per
+// sample.
+type MultiLabel struct {
+	rejector
+
+	weight     float64 // total weight observed
+	exact      float64 // weight of exact matches
+	jaccardSum float64 // weighted sum of Jaccard scores
+	symDiffSum float64 // weighted sum of mismatched labels
+
+	tp, fp, fn []float64 // per-label counts
+
+	mu sync.RWMutex
+}
+
+// MultiLabelSnapshot is a point-in-time snapshot of a MultiLabel metric.
+type MultiLabelSnapshot struct {
+	Weight         float64       // total weight observed
+	HammingLoss    float64       // fraction of incorrectly predicted labels
+	SubsetAccuracy float64       // rate of exact matches
+	Jaccard        float64       // mean Jaccard index
+	MicroF1        float64       // micro-averaged F1 score
+	MacroF1        float64       // macro-averaged F1 score
+	Labels         []LabelCounts // confusion counts of each label
+	Rejected       Rejections    // observations rejected by validation
+}
+
+// NewMultiLabel inits a new metric.
+func NewMultiLabel() *MultiLabel {
+	return &MultiLabel{}
+}
+
+// Reset resets state.
+func (m *MultiLabel) Reset() {
+	m.mu.Lock()
+	m.reset()
+	m.mu.Unlock()
+	m.resetRejected()
+}
+
+// Observe records an observation of the actual vs the predicted label sets.
+func (m *MultiLabel) Observe(actual, predicted []int) {
+	m.ObserveWeight(actual, predicted, 1.0)
+}
+
+// ObserveWeight records an observation of the actual vs the predicted label sets with a given weight.
+func (m *MultiLabel) ObserveWeight(actual, predicted []int, weight float64) {
+	_ = m.ObserveWeightStrict(actual, predicted, weight)
+}
+
+// ObserveWeightStrict is like ObserveWeight, but returns an error if the observation was
+// rejected by validation, see Rejected.
+func (m *MultiLabel) ObserveWeightStrict(actual, predicted []int, weight float64) error {
+	if err := validateMultiLabel(actual, predicted, weight); err != nil {
+		return m.reject(err)
+	}
+
+	flags, size := labelFlags(actual, predicted)
+
+	m.mu.Lock()
+	m.observe(flags, size, weight)
+	m.mu.Unlock()
+	return nil
+}
+
+// ObserveBatch records a batch of observations of actual vs predicted label sets.
+// Weights are optional, if given, they must match the number of observations.
+// Returns ErrLengthMismatch if the lengths of the inputs differ and a *BatchError
+// if observations were rejected.
+func (m *MultiLabel) ObserveBatch(actual, predicted [][]int, weights []float64) error {
+	if err := validateBatch(len(actual), weights, len(predicted)); err != nil {
+		return m.rejectBatch(len(actual), err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var berr BatchError
+	for i := range actual {
+		weight := batchWeight(weights, i)
+		if err := validateMultiLabel(actual[i], predicted[i], weight); err != nil {
+			berr.add(i, m.reject(err))
+			continue
+		}
+		flags, size := labelFlags(actual[i], predicted[i])
+		m.observe(flags, size, weight)
+	}
+	return berr.errOrNil()
+}
+
+// TotalWeight returns the total weight observed.
+func (m *MultiLabel) TotalWeight() float64 {
+	m.mu.RLock()
+	weight := m.weight
+	m.mu.RUnlock()
+	return weight
+}
+
+// NumLabels returns the number of labels, derived from the highest label observed.
+func (m *MultiLabel) NumLabels() int {
+	m.mu.RLock()
+	n := len(m.tp)
+	m.mu.RUnlock()
+	return n
+}
+
+// HammingLoss calculates the fraction of labels that are incorrectly predicted.
+// Only labels which were observed in actual or predicted label sets are counted.
+// https://en.wikipedia.org/wiki/Multi-label_classification#Statistics_and_evaluation_metrics
+func (m *MultiLabel) HammingLoss() float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if n := m.weight * float64(m.numObserved()); n > 0 {
+		return m.symDiffSum / n
+	}
+	return 0.0
+}
+
+// SubsetAccuracy calculates the rate of samples where the predicted label set
+// exactly matches the actual label set.
+func (m *MultiLabel) SubsetAccuracy() float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.weight > 0 {
+		return m.exact / m.weight
+	}
+	return 0.0
+}
+
+// Jaccard calculates the mean Jaccard index (intersection over union) of the
+// actual and predicted label sets. Samples with empty actual and predicted label
+// sets score 1.
+// https://en.wikipedia.org/wiki/Jaccard_index
+func (m *MultiLabel) Jaccard() float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.weight > 0 {
+		return m.jaccardSum / m.weight
+	}
+	return 0.0
+}
+
+// Counts returns the binary confusion counts for label x.
+func (m *MultiLabel) Counts(x int) LabelCounts {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.counts(x)
+}
+
+// Precision calculates the positive predictive value for label x.
+func (m *MultiLabel) Precision(x int) float64 {
+	c := m.Counts(x)
+	if sum := c.TP + c.FP; sum > 0 {
+		return c.TP / sum
+	}
+	return 0.0
+}
+
+// Sensitivity calculates the recall for label x.
+func (m *MultiLabel) Sensitivity(x int) float64 {
+	c := m.Counts(x)
+	if sum := c.TP + c.FN; sum > 0 {
+		return c.TP / sum
+	}
+	return 0.0
+}
+
+// F1 calculates the F1 score for label x.
+func (m *MultiLabel) F1(x int) float64 {
+	c := m.Counts(x)
+	return f1Score(c.TP, c.FP, c.FN)
+}
+
+// MicroF1 calculates the F1 score globally, by counting the total true positives,
+// false negatives and false positives across all labels.
+func (m *MultiLabel) MicroF1() float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var tp, fp, fn float64
+	for x := range m.tp {
+		tp += m.tp[x]
+		fp += m.fp[x]
+		fn += m.fn[x]
+	}
+	return f1Score(tp, fp, fn)
+}
+
+// MacroF1 calculates the unweighted mean of F1 scores across all labels which
+// were observed in actual or predicted label sets.
+func (m *MultiLabel) MacroF1() float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var sum float64
+	var n int
+	for x := range m.tp {
+		if m.isObserved(x) {
+			sum += f1Score(m.tp[x], m.fp[x], m.fn[x])
+			n++
+		}
+	}
+	if n == 0 {
+		return 0.0
+	}
+	return sum / float64(n)
+}
+
+// Snapshot returns a consistent point-in-time snapshot of all values.
+func (m *MultiLabel) Snapshot() MultiLabelSnapshot {
+	m.mu.RLock()
+	c := m.clone()
+	m.mu.RUnlock()
+
+	snap := c.snapshot()
+	snap.Rejected = m.Rejected()
+	return snap
+}
+
+// SnapshotAndReset atomically returns a snapshot of all values and resets state.
+func (m *MultiLabel) SnapshotAndReset() MultiLabelSnapshot {
+	m.mu.Lock()
+	c := m.clone()
+	m.reset()
+	m.mu.Unlock()
+
+	snap := c.snapshot()
+	snap.Rejected = m.harvestRejected()
+	return snap
+}
+
+func (m *MultiLabel) observe(flags map[int]byte, size int, weight float64) {
+	var inter, union int
+	for _, f := range flags {
+		if f == 3 {
+			inter++
+		}
+		union++
+	}
+
+	jaccard := 1.0
+	if union != 0 {
+		jaccard = float64(inter) / float64(union)
+	}
+
+	for len(m.tp) < size {
+		m.tp = append(m.tp, 0)
+		m.fp = append(m.fp, 0)
+		m.fn = append(m.fn, 0)
+	}
+
+	m.weight += weight
+	if inter == union {
+		m.exact += weight
+	}
+	m.jaccardSum += jaccard * weight
+	m.symDiffSum += float64(union-inter) * weight
+
+	for x, f := range flags {
+		switch f {
+		case 1:
+			m.fn[x] += weight
+		case 2:
+			m.fp[x] += weight
+		case 3:
+			m.tp[x] += weight
+		}
+	}
+}
+
+func (m *MultiLabel) clone() *MultiLabel {
+	return &MultiLabel{
+		weight:     m.weight,
+		exact:      m.exact,
+		jaccardSum: m.jaccardSum,
+		symDiffSum: m.symDiffSum,
+		tp:         append([]float64(nil), m.tp...),
+		fp:         append([]float64(nil), m.fp...),
+		fn:         append([]float64(nil), m.fn...),
+	}
+}
+
+func (m *MultiLabel) reset() {
+	m.weight = 0
+	m.exact = 0
+	m.jaccardSum = 0
+	m.symDiffSum = 0
+	m.tp = nil
+	m.fp = nil
+	m.fn = nil
+}
+
+func (m *MultiLabel) snapshot() MultiLabelSnapshot {
+	s := MultiLabelSnapshot{
+		Weight:         m.TotalWeight(),
+		HammingLoss:    m.HammingLoss(),
+		SubsetAccuracy: m.SubsetAccuracy(),
+		Jaccard:        m.Jaccard(),
+		MicroF1:        m.MicroF1(),
+		MacroF1:        m.MacroF1(),
+		Labels:         make([]LabelCounts, len(m.tp)),
+	}
+	for x := range s.Labels {
+		s.Labels[x] = m.Counts(x)
+	}
+	return s
+}
+
+func (m *MultiLabel) counts(x int) LabelCounts {
+	if x < 0 || x >= len(m.tp) {
+		return LabelCounts{TN: m.weight}
+	}
+
+	tp, fp, fn := m.tp[x], m.fp[x], m.fn[x]
+	return LabelCounts{TP: tp, FP: fp, FN: fn, TN: m.weight - tp - fp - fn}
+}
+
+// isObserved returns true if label x was part of any actual or predicted label set.
+func (m *MultiLabel) isObserved(x int) bool {
+	return m.tp[x]+m.fp[x]+m.fn[x] > 0
+}
+
+// numObserved returns the number of labels which were part of any actual or predicted
+// label set.
+func (m *MultiLabel) numObserved() int {
+	var n int
+	for x := range m.tp {
+		if m.isObserved(x) {
+			n++
+		}
+	}
+	return n
+}
+
+func f1Score(tp, fp, fn float64) float64 {
+	if div := 2*tp + fp + fn; div > 0 {
+		return 2 * tp / div
+	}
+	return 0.0
+}
+
+// labelFlags returns the flags of all labels in actual and/or predicted label sets
+// (1 = actual, 2 = predicted, 3 = both) and the number of labels required to
+// store them.
+func labelFlags(actual, predicted []int) (map[int]byte, int) {
+	size := 0
+	flags := make(map[int]byte, len(actual)+len(predicted))
+	for _, x := range actual {
+		flags[x] |= 1
+		size = maxInt(size, x+1)
+	}
+	for _, x := range predicted {
+		flags[x] |= 2
+		size = maxInt(size, x+1)
+	}
+	return flags, size
+}
+
+// validateMultiLabel validates an observation of actual vs predicted label sets.
+func validateMultiLabel(actual, predicted []int, weight float64) error {
+	for _, x := range actual {
+		if !isValidCategory(x) {
+			return ErrInvalidCategory
+		}
+	}
+	for _, x := range predicted {
+		if !isValidCategory(x) {
+			return ErrInvalidCategory
+		}
+	}
+	return validateWeight(weight)
+}
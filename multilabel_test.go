@@ -0,0 +1,91 @@
+package mlmetrics_test
+
+import (
+	. "github.com/bsm/ginkgo"
+	. "github.com/bsm/gomega"
+	"github.com/bsm/mlmetrics"
+)
+
+var _ = Describe("MultiLabel", func() {
+	var subject *mlmetrics.MultiLabel
+
+	BeforeEach(func() {
+		subject = mlmetrics.NewMultiLabel()
+		subject.Observe([]int{0, 1}, []int{1, 0})
+		subject.Observe([]int{0, 2}, []int{0})
+		subject.Observe([]int{1}, []int{1, 2, 2})
+		subject.Observe(nil, []int{2})
+	})
+
+	It("should calculate stats", func() {
+		Expect(subject.TotalWeight()).To(Equal(4.0))
+		Expect(subject.NumLabels()).To(Equal(3))
+		Expect(subject.HammingLoss()).To(BeNumerically("~", 0.25, 0.001))
+		Expect(subject.SubsetAccuracy()).To(BeNumerically("~", 0.25, 0.001))
+		Expect(subject.Jaccard()).To(BeNumerically("~", 0.5, 0.001))
+	})
+
+	It("should calculate per-label stats", func() {
+		Expect(subject.Counts(0)).To(Equal(mlmetrics.LabelCounts{TP: 2, TN: 2}))
+		Expect(subject.Counts(2)).To(Equal(mlmetrics.LabelCounts{FP: 2, FN: 1, TN: 1}))
+		Expect(subject.Counts(3)).To(Equal(mlmetrics.LabelCounts{TN: 4}))
+
+		Expect(subject.Precision(1)).To(Equal(1.0))
+		Expect(subject.Precision(2)).To(Equal(0.0))
+		Expect(subject.Sensitivity(1)).To(Equal(1.0))
+		Expect(subject.F1(0)).To(Equal(1.0))
+		Expect(subject.F1(2)).To(Equal(0.0))
+	})
+
+	It("should calculate averaged F1 scores", func() {
+		Expect(subject.MicroF1()).To(BeNumerically("~", 0.727, 0.001))
+		Expect(subject.MacroF1()).To(BeNumerically("~", 0.667, 0.001))
+	})
+
+	It("should calculate weighted stats", func() {
+		subject.ObserveWeight([]int{2}, []int{2}, 4.0)
+		Expect(subject.TotalWeight()).To(Equal(8.0))
+		Expect(subject.SubsetAccuracy()).To(BeNumerically("~", 0.625, 0.001))
+		Expect(subject.Counts(2)).To(Equal(mlmetrics.LabelCounts{TP: 4, FP: 2, FN: 1, TN: 1}))
+	})
+
+	It("should only average observed labels", func() {
+		subject.Reset()
+		subject.Observe([]int{0, 9}, []int{0, 9})
+		Expect(subject.NumLabels()).To(Equal(10))
+		Expect(subject.MacroF1()).To(Equal(1.0))
+		Expect(subject.HammingLoss()).To(Equal(0.0))
+
+		subject.Observe([]int{0}, []int{9})
+		Expect(subject.MacroF1()).To(BeNumerically("~", 0.667, 0.001))
+		Expect(subject.HammingLoss()).To(Equal(0.5))
+	})
+
+	It("should ignore invalid labels", func() {
+		subject.Observe([]int{-1}, []int{1})
+		subject.ObserveWeight([]int{1}, []int{1}, 0)
+		Expect(subject.TotalWeight()).To(Equal(4.0))
+	})
+
+	It("should handle blanks", func() {
+		subject.Reset()
+		Expect(subject.TotalWeight()).To(Equal(0.0))
+		Expect(subject.NumLabels()).To(Equal(0))
+		Expect(subject.HammingLoss()).To(Equal(0.0))
+		Expect(subject.SubsetAccuracy()).To(Equal(0.0))
+		Expect(subject.Jaccard()).To(Equal(0.0))
+		Expect(subject.MicroF1()).To(Equal(0.0))
+		Expect(subject.MacroF1()).To(Equal(0.0))
+	})
+
+	It("should observe batches", func() {
+		expected := mlmetrics.NewMultiLabel()
+		expected.Observe([]int{0, 1}, []int{1})
+		expected.ObserveWeight([]int{2}, []int{2, 0}, 2)
+
+		subject = mlmetrics.NewMultiLabel()
+		Expect(subject.ObserveBatch([][]int{{0, 1}, {2}, {-1}}, [][]int{{1}, {2, 0}, {0}}, []float64{1, 2, 1})).To(MatchError(mlmetrics.ErrInvalidCategory))
+		Expect(subject.Snapshot()).To(equalSnapshot(expected.Snapshot()))
+		Expect(subject.ObserveBatch([][]int{{0}}, nil, nil)).To(MatchError(mlmetrics.ErrLengthMismatch))
+	})
+})
@@ -0,0 +1,240 @@
+package mlmetrics
+
+import (
+	"sync"
+)
+
+// MultiOutputAverage defines how scores of multiple outputs are aggregated.
+type MultiOutputAverage int
+
+const (
+	// UniformAverage averages scores of all outputs with uniform weight.
+	UniformAverage MultiOutputAverage = iota
+	// VarianceWeighted averages scores weighted by the variance of each
+	// output's actual values.
+	VarianceWeighted
+)
+
+// MultiRegression is a regression evaluator for models which predict multiple
+// target values at once.
+type MultiRegression struct {
+	rejector
+	outputs []regressionState
+	mu      sync.RWMutex
+}
+
+// MultiRegressionSnapshot is a point-in-time snapshot of a MultiRegression metric.
+type MultiRegressionSnapshot struct {
+	Outputs     []RegressionSnapshot // snapshots of each output
+	AverageMAE  float64              // uniform average of mean absolute errors
+	AverageRMSE float64              // uniform average of root mean squared errors
+	AverageR2   float64              // uniform average of R² coefficients
+	Rejected    Rejections           // observations rejected by validation
+}
+
+// NewMultiRegression inits a new metric.
+func NewMultiRegression() *MultiRegression {
+	return &MultiRegression{}
+}
+
+// Reset resets state.
+func (m *MultiRegression) Reset() {
+	m.mu.Lock()
+	m.reset()
+	m.mu.Unlock()
+	m.resetRejected()
+}
+
+// Observe records an observation of the actual vs the predicted values.
+func (m *MultiRegression) Observe(actual, predicted []float64) {
+	m.ObserveWeight(actual, predicted, 1.0)
+}
+
+// ObserveWeight records an observation of the actual vs the predicted values with a given weight.
+// Observations with mismatching numbers of actual and predicted values are rejected.
+func (m *MultiRegression) ObserveWeight(actual, predicted []float64, weight float64) {
+	_ = m.ObserveWeightStrict(actual, predicted, weight)
+}
+
+// ObserveWeightStrict is like ObserveWeight, but returns an error if the observation was
+// rejected by validation, see Rejected.
+func (m *MultiRegression) ObserveWeightStrict(actual, predicted []float64, weight float64) error {
+	if err := validateMultiRegression(actual, predicted, weight); err != nil {
+		return m.reject(err)
+	}
+
+	m.mu.Lock()
+	m.observe(actual, predicted, weight)
+	m.mu.Unlock()
+	return nil
+}
+
+// ObserveBatch records a batch of observations of actual vs predicted values.
+// Weights are optional, if given, they must match the number of observations.
+// Returns ErrLengthMismatch if the lengths of the inputs differ and a *BatchError
+// if observations were rejected.
+func (m *MultiRegression) ObserveBatch(actual, predicted [][]float64, weights []float64) error {
+	if err := validateBatch(len(actual), weights, len(predicted)); err != nil {
+		return m.rejectBatch(len(actual), err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var berr BatchError
+	for i := range actual {
+		weight := batchWeight(weights, i)
+		if err := validateMultiRegression(actual[i], predicted[i], weight); err != nil {
+			berr.add(i, m.reject(err))
+			continue
+		}
+		m.observe(actual[i], predicted[i], weight)
+	}
+	return berr.errOrNil()
+}
+
+// Outputs returns the number of outputs.
+func (m *MultiRegression) Outputs() int {
+	m.mu.RLock()
+	n := len(m.outputs)
+	m.mu.RUnlock()
+	return n
+}
+
+// TotalWeight returns the total weight observed for output x.
+func (m *MultiRegression) TotalWeight(x int) float64 {
+	return m.score(x, func(s *regressionState) float64 { return s.weight })
+}
+
+// MAE calculates the mean absolute error of output x.
+func (m *MultiRegression) MAE(x int) float64 {
+	return m.score(x, (*regressionState).mae)
+}
+
+// RMSE calculates the root mean squared error of output x.
+func (m *MultiRegression) RMSE(x int) float64 {
+	return m.score(x, (*regressionState).rmse)
+}
+
+// R2 calculates the R² coefficient of determination of output x.
+func (m *MultiRegression) R2(x int) float64 {
+	return m.score(x, (*regressionState).r2)
+}
+
+// AverageMAE calculates the mean absolute error across all outputs.
+func (m *MultiRegression) AverageMAE(avg MultiOutputAverage) float64 {
+	return m.average(avg, (*regressionState).mae)
+}
+
+// AverageRMSE calculates the root mean squared error across all outputs.
+func (m *MultiRegression) AverageRMSE(avg MultiOutputAverage) float64 {
+	return m.average(avg, (*regressionState).rmse)
+}
+
+// AverageR2 calculates the R² coefficient of determination across all outputs.
+func (m *MultiRegression) AverageR2(avg MultiOutputAverage) float64 {
+	return m.average(avg, (*regressionState).r2)
+}
+
+// Snapshot returns a consistent point-in-time snapshot of all values.
+func (m *MultiRegression) Snapshot() MultiRegressionSnapshot {
+	m.mu.RLock()
+	c := m.clone()
+	m.mu.RUnlock()
+
+	snap := c.snapshot()
+	snap.Rejected = m.Rejected()
+	return snap
+}
+
+// SnapshotAndReset atomically returns a snapshot of all values and resets state.
+func (m *MultiRegression) SnapshotAndReset() MultiRegressionSnapshot {
+	m.mu.Lock()
+	c := m.clone()
+	m.reset()
+	m.mu.Unlock()
+
+	snap := c.snapshot()
+	snap.Rejected = m.harvestRejected()
+	return snap
+}
+
+func (m *MultiRegression) score(x int, fn func(*regressionState) float64) float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if x < 0 || x >= len(m.outputs) {
+		return 0.0
+	}
+	return fn(&m.outputs[x])
+}
+
+func (m *MultiRegression) average(avg MultiOutputAverage, fn func(*regressionState) float64) float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.outputs) == 0 {
+		return 0.0
+	}
+
+	if avg == VarianceWeighted {
+		var sum, weight float64
+		for i := range m.outputs {
+			v := m.outputs[i].variance()
+			sum += fn(&m.outputs[i]) * v
+			weight += v
+		}
+		if weight > 0 {
+			return sum / weight
+		}
+	}
+
+	var sum float64
+	for i := range m.outputs {
+		sum += fn(&m.outputs[i])
+	}
+	return sum / float64(len(m.outputs))
+}
+
+func (m *MultiRegression) observe(actual, predicted []float64, weight float64) {
+	for len(m.outputs) < len(actual) {
+		m.outputs = append(m.outputs, regressionState{})
+	}
+	for i := range actual {
+		m.outputs[i].observe(actual[i], predicted[i], weight)
+	}
+}
+
+func (m *MultiRegression) clone() *MultiRegression {
+	return &MultiRegression{outputs: append([]regressionState(nil), m.outputs...)}
+}
+
+func (m *MultiRegression) reset() {
+	m.outputs = nil
+}
+
+func (m *MultiRegression) snapshot() MultiRegressionSnapshot {
+	s := MultiRegressionSnapshot{
+		Outputs:     make([]RegressionSnapshot, len(m.outputs)),
+		AverageMAE:  m.AverageMAE(UniformAverage),
+		AverageRMSE: m.AverageRMSE(UniformAverage),
+		AverageR2:   m.AverageR2(UniformAverage),
+	}
+	for i := range m.outputs {
+		s.Outputs[i] = m.outputs[i].snapshot()
+	}
+	return s
+}
+
+// validateMultiRegression validates an observation of actual vs predicted values.
+func validateMultiRegression(actual, predicted []float64, weight float64) error {
+	if len(actual) != len(predicted) {
+		return ErrLengthMismatch
+	}
+	for i := range actual {
+		if !isValidNumeric(actual[i]) || !isValidNumeric(predicted[i]) {
+			return ErrInvalidNumeric
+		}
+	}
+	return validateWeight(weight)
+}
@@ -0,0 +1,82 @@
+package mlmetrics_test
+
+import (
+	"math"
+
+	. "github.com/bsm/ginkgo"
+	. "github.com/bsm/gomega"
+	"github.com/bsm/mlmetrics"
+)
+
+var _ = Describe("MultiRegression", func() {
+	var subject *mlmetrics.MultiRegression
+
+	BeforeEach(func() {
+		subject = mlmetrics.NewMultiRegression()
+		subject.Observe([]float64{0.5, 1}, []float64{0, 2})
+		subject.Observe([]float64{-1, 1}, []float64{-1, 2})
+		subject.Observe([]float64{7, -6}, []float64{8, -5})
+	})
+
+	It("should calculate per-output stats", func() {
+		Expect(subject.Outputs()).To(Equal(2))
+		Expect(subject.TotalWeight(0)).To(Equal(3.0))
+		Expect(subject.MAE(0)).To(BeNumerically("~", 0.500, 0.001))
+		Expect(subject.MAE(1)).To(BeNumerically("~", 1.000, 0.001))
+		Expect(subject.RMSE(0)).To(BeNumerically("~", 0.645, 0.001))
+		Expect(subject.RMSE(1)).To(BeNumerically("~", 1.000, 0.001))
+		Expect(subject.R2(0)).To(BeNumerically("~", 0.965, 0.001))
+		Expect(subject.R2(1)).To(BeNumerically("~", 0.908, 0.001))
+		Expect(subject.MAE(2)).To(Equal(0.0))
+	})
+
+	It("should calculate aggregated stats", func() {
+		Expect(subject.AverageMAE(mlmetrics.UniformAverage)).To(BeNumerically("~", 0.750, 0.001))
+		Expect(subject.AverageRMSE(mlmetrics.UniformAverage)).To(BeNumerically("~", 0.823, 0.001))
+		Expect(subject.AverageR2(mlmetrics.UniformAverage)).To(BeNumerically("~", 0.937, 0.001))
+
+		Expect(subject.AverageMAE(mlmetrics.VarianceWeighted)).To(BeNumerically("~", 0.737, 0.001))
+		Expect(subject.AverageR2(mlmetrics.VarianceWeighted)).To(BeNumerically("~", 0.938, 0.001))
+	})
+
+	It("should ignore mismatching observations", func() {
+		subject.Observe([]float64{1, 2}, []float64{1})
+		subject.ObserveWeight([]float64{1, 2}, []float64{1, 2}, 0)
+		Expect(subject.TotalWeight(0)).To(Equal(3.0))
+	})
+
+	It("should handle blanks", func() {
+		subject.Reset()
+		Expect(subject.Outputs()).To(Equal(0))
+		Expect(subject.MAE(0)).To(Equal(0.0))
+		Expect(subject.AverageMAE(mlmetrics.UniformAverage)).To(Equal(0.0))
+		Expect(subject.AverageR2(mlmetrics.VarianceWeighted)).To(Equal(0.0))
+	})
+
+	It("should observe batches", func() {
+		expected := mlmetrics.NewMultiRegression()
+		expected.Observe([]float64{1, 2}, []float64{2, 2})
+		expected.ObserveWeight([]float64{3, 4}, []float64{3, 5}, 2)
+
+		subject = mlmetrics.NewMultiRegression()
+		Expect(subject.ObserveBatch([][]float64{{1, 2}, {3, 4}}, [][]float64{{2, 2}, {3, 5}}, []float64{1, 2})).To(Succeed())
+		Expect(subject.Snapshot()).To(Equal(expected.Snapshot()))
+		Expect(subject.ObserveBatch([][]float64{{1, 2}}, nil, nil)).To(MatchError(mlmetrics.ErrLengthMismatch))
+		Expect(subject.Rejected()).To(Equal(mlmetrics.Rejections{LengthMismatch: 1}))
+
+		err := subject.ObserveBatch([][]float64{{1, 2}, {3, 4}}, [][]float64{{2}, {3, 5}}, nil)
+		Expect(err).To(MatchError(&mlmetrics.BatchError{Index: 0, Err: mlmetrics.ErrLengthMismatch, Rejected: 1}))
+		Expect(subject.TotalWeight(0)).To(Equal(4.0))
+		Expect(subject.Rejected()).To(Equal(mlmetrics.Rejections{LengthMismatch: 2}))
+	})
+
+	It("should reject invalid observations", func() {
+		subject = mlmetrics.NewMultiRegression()
+		subject.Observe([]float64{1, 2}, []float64{2})
+		subject.Observe([]float64{1, 2}, []float64{2, math.NaN()})
+		Expect(subject.Outputs()).To(BeZero())
+		Expect(subject.Rejected()).To(Equal(mlmetrics.Rejections{LengthMismatch: 1, InvalidNumeric: 1}))
+
+		Expect(subject.ObserveWeightStrict([]float64{1, 2}, []float64{2}, 1)).To(MatchError(mlmetrics.ErrLengthMismatch))
+	})
+})
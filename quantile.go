@@ -0,0 +1,408 @@
+package mlmetrics
+
+import (
+	"sync"
+)
+
+// QuantileLoss is the pinball loss of a probabilistic regression model, which
+// predicts quantiles of the target distribution rather than a point estimate.
+// https://en.wikipedia.org/wiki/Quantile_regression
+type QuantileLoss struct {
+	rejector
+
+	weight  float64 // total weight observed
+	lossSum float64 // weighted sum of pinball losses
+	below   float64 // weight of actual values at or below the predicted quantile
+
+	mu sync.RWMutex
+}
+
+// QuantileLossSnapshot is a point-in-time snapshot of a QuantileLoss metric.
+type QuantileLossSnapshot struct {
+	Weight    float64    // total weight observed
+	Score     float64    // mean pinball loss
+	BelowRate float64    // rate of actual values at or below the predicted quantile
+	Rejected  Rejections // observations rejected by validation
+}
+
+// NewQuantileLoss inits a new metric.
+func NewQuantileLoss() *QuantileLoss {
+	return &QuantileLoss{}
+}
+
+// Reset resets state.
+func (m *QuantileLoss) Reset() {
+	m.mu.Lock()
+	m.reset()
+	m.mu.Unlock()
+	m.resetRejected()
+}
+
+// Observe records an observation of the actual value vs the predicted quantile
+// tau, where tau is between 0 and 1. For example, for a P90 forecast tau is 0.9.
+func (m *QuantileLoss) Observe(actual, predicted, tau float64) {
+	m.ObserveWeight(actual, predicted, tau, 1.0)
+}
+
+// ObserveWeight records an observation of the actual value vs the predicted quantile tau
+// with a given weight.
+func (m *QuantileLoss) ObserveWeight(actual, predicted, tau, weight float64) {
+	_ = m.ObserveWeightStrict(actual, predicted, tau, weight)
+}
+
+// ObserveWeightStrict is like ObserveWeight, but returns an error if the observation was
+// rejected by validation, see Rejected.
+func (m *QuantileLoss) ObserveWeightStrict(actual, predicted, tau, weight float64) error {
+	if err := validateQuantile(actual, predicted, tau, weight); err != nil {
+		return m.reject(err)
+	}
+
+	m.mu.Lock()
+	m.observe(actual, predicted, tau, weight)
+	m.mu.Unlock()
+	return nil
+}
+
+// ObserveBatch records a batch of observations of actual values vs predicted quantiles.
+// Weights are optional, if given, they must match the number of observations.
+// Returns ErrLengthMismatch if the lengths of the inputs differ and a *BatchError
+// if observations were rejected.
+func (m *QuantileLoss) ObserveBatch(actual, predicted, tau []float64, weights []float64) error {
+	if err := validateBatch(len(actual), weights, len(predicted), len(tau)); err != nil {
+		return m.rejectBatch(len(actual), err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var berr BatchError
+	for i := range actual {
+		weight := batchWeight(weights, i)
+		if err := validateQuantile(actual[i], predicted[i], tau[i], weight); err != nil {
+			berr.add(i, m.reject(err))
+			continue
+		}
+		m.observe(actual[i], predicted[i], tau[i], weight)
+	}
+	return berr.errOrNil()
+}
+
+// TotalWeight returns the total weight observed.
+func (m *QuantileLoss) TotalWeight() float64 {
+	m.mu.RLock()
+	weight := m.weight
+	m.mu.RUnlock()
+	return weight
+}
+
+// Score calculates the mean pinball loss.
+func (m *QuantileLoss) Score() float64 {
+	m.mu.RLock()
+	weight := m.weight
+	lossSum := m.lossSum
+	m.mu.RUnlock()
+
+	if weight > 0 {
+		return lossSum / weight
+	}
+	return 0.0
+}
+
+// BelowRate returns the rate of actual values that were at or below the predicted
+// quantile. For a well calibrated model observed with a single tau, the
+// rate should be close to tau.
+func (m *QuantileLoss) BelowRate() float64 {
+	m.mu.RLock()
+	weight := m.weight
+	below := m.below
+	m.mu.RUnlock()
+
+	if weight > 0 {
+		return below / weight
+	}
+	return 0.0
+}
+
+// Snapshot returns a consistent point-in-time snapshot of all values.
+func (m *QuantileLoss) Snapshot() QuantileLossSnapshot {
+	m.mu.RLock()
+	c := m.clone()
+	m.mu.RUnlock()
+
+	snap := c.snapshot()
+	snap.Rejected = m.Rejected()
+	return snap
+}
+
+// SnapshotAndReset atomically returns a snapshot of all values and resets state.
+func (m *QuantileLoss) SnapshotAndReset() QuantileLossSnapshot {
+	m.mu.Lock()
+	c := m.clone()
+	m.reset()
+	m.mu.Unlock()
+
+	snap := c.snapshot()
+	snap.Rejected = m.harvestRejected()
+	return snap
+}
+
+func (m *QuantileLoss) observe(actual, predicted, tau, weight float64) {
+	m.weight += weight
+	m.lossSum += pinballLoss(actual, predicted, tau) * weight
+	if actual <= predicted {
+		m.below += weight
+	}
+}
+
+func (m *QuantileLoss) clone() *QuantileLoss {
+	return &QuantileLoss{weight: m.weight, lossSum: m.lossSum, below: m.below}
+}
+
+func (m *QuantileLoss) reset() {
+	m.weight = 0
+	m.lossSum = 0
+	m.below = 0
+}
+
+func (m *QuantileLoss) snapshot() QuantileLossSnapshot {
+	return QuantileLossSnapshot{
+		Weight:    m.TotalWeight(),
+		Score:     m.Score(),
+		BelowRate: m.BelowRate(),
+	}
+}
+
+func pinballLoss(actual, predicted, tau float64) float64 {
+	delta := actual - predicted
+	if delta >= 0 {
+		return tau * delta
+	}
+	return (tau - 1) * delta
+}
+
+// --------------------------------------------------------------------
+
+// IntervalCoverage evaluates prediction intervals. It reports the rate of actual values
+// that fall within the predicted [lower, upper] bounds, the mean width of the intervals
+// and the Winkler interval score.
+// https://otexts.com/fpp3/distaccuracy.html#winkler-score
+type IntervalCoverage struct {
+	rejector
+
+	alpha float64
+
+	weight   float64 // total weight observed
+	covered  float64 // weight of covered observations
+	widthSum float64 // weighted sum of interval widths
+	scoreSum float64 // weighted sum of Winkler scores
+
+	mu sync.RWMutex
+}
+
+// IntervalCoverageSnapshot is a point-in-time snapshot of an IntervalCoverage metric.
+type IntervalCoverageSnapshot struct {
+	Weight    float64    // total weight observed
+	Rate      float64    // rate of covered actual values
+	MeanWidth float64    // mean interval width
+	Winkler   float64    // mean Winkler score
+	Rejected  Rejections // observations rejected by validation
+}
+
+// NewIntervalCoverage inits a new metric for intervals with a nominal coverage
+// of (1 - alpha). For example, the interval between P10 and P90 forecasts has an
+// alpha of 0.2. Alpha is only used by Winkler score calculations. Default: 0.05.
+func NewIntervalCoverage(alpha float64) *IntervalCoverage {
+	if alpha <= 0 || alpha >= 1 {
+		alpha = 0.05
+	}
+	return &IntervalCoverage{alpha: alpha}
+}
+
+// Reset resets state.
+func (m *IntervalCoverage) Reset() {
+	m.mu.Lock()
+	m.reset()
+	m.mu.Unlock()
+	m.resetRejected()
+}
+
+// Observe records an observation of the actual value vs the predicted interval.
+func (m *IntervalCoverage) Observe(actual, lower, upper float64) {
+	m.ObserveWeight(actual, lower, upper, 1.0)
+}
+
+// ObserveWeight records an observation of the actual value vs the predicted interval
+// with a given weight.
+func (m *IntervalCoverage) ObserveWeight(actual, lower, upper, weight float64) {
+	_ = m.ObserveWeightStrict(actual, lower, upper, weight)
+}
+
+// ObserveWeightStrict is like ObserveWeight, but returns an error if the observation was
+// rejected by validation, see Rejected.
+func (m *IntervalCoverage) ObserveWeightStrict(actual, lower, upper, weight float64) error {
+	if err := validateInterval(actual, lower, upper, weight); err != nil {
+		return m.reject(err)
+	}
+
+	m.mu.Lock()
+	m.observe(actual, lower, upper, weight)
+	m.mu.Unlock()
+	return nil
+}
+
+// ObserveBatch records a batch of observations of actual values vs predicted intervals.
+// Weights are optional, if given, they must match the number of observations.
+// Returns ErrLengthMismatch if the lengths of the inputs differ and a *BatchError
+// if observations were rejected.
+func (m *IntervalCoverage) ObserveBatch(actual, lower, upper []float64, weights []float64) error {
+	if err := validateBatch(len(actual), weights, len(lower), len(upper)); err != nil {
+		return m.rejectBatch(len(actual), err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var berr BatchError
+	for i := range actual {
+		weight := batchWeight(weights, i)
+		if err := validateInterval(actual[i], lower[i], upper[i], weight); err != nil {
+			berr.add(i, m.reject(err))
+			continue
+		}
+		m.observe(actual[i], lower[i], upper[i], weight)
+	}
+	return berr.errOrNil()
+}
+
+// TotalWeight returns the total weight observed.
+func (m *IntervalCoverage) TotalWeight() float64 {
+	m.mu.RLock()
+	weight := m.weight
+	m.mu.RUnlock()
+	return weight
+}
+
+// Rate returns the rate of actual values covered by the predicted intervals.
+func (m *IntervalCoverage) Rate() float64 {
+	m.mu.RLock()
+	weight := m.weight
+	covered := m.covered
+	m.mu.RUnlock()
+
+	if weight > 0 {
+		return covered / weight
+	}
+	return 0.0
+}
+
+// MeanWidth returns the mean width of the predicted intervals.
+func (m *IntervalCoverage) MeanWidth() float64 {
+	m.mu.RLock()
+	weight := m.weight
+	widthSum := m.widthSum
+	m.mu.RUnlock()
+
+	if weight > 0 {
+		return widthSum / weight
+	}
+	return 0.0
+}
+
+// Winkler returns the mean Winkler score, which penalises wide intervals as well
+// as actual values outside the interval. Lower is better.
+func (m *IntervalCoverage) Winkler() float64 {
+	m.mu.RLock()
+	weight := m.weight
+	scoreSum := m.scoreSum
+	m.mu.RUnlock()
+
+	if weight > 0 {
+		return scoreSum / weight
+	}
+	return 0.0
+}
+
+// Snapshot returns a consistent point-in-time snapshot of all values.
+func (m *IntervalCoverage) Snapshot() IntervalCoverageSnapshot {
+	m.mu.RLock()
+	c := m.clone()
+	m.mu.RUnlock()
+
+	snap := c.snapshot()
+	snap.Rejected = m.Rejected()
+	return snap
+}
+
+// SnapshotAndReset atomically returns a snapshot of all values and resets state.
+func (m *IntervalCoverage) SnapshotAndReset() IntervalCoverageSnapshot {
+	m.mu.Lock()
+	c := m.clone()
+	m.reset()
+	m.mu.Unlock()
+
+	snap := c.snapshot()
+	snap.Rejected = m.harvestRejected()
+	return snap
+}
+
+func (m *IntervalCoverage) observe(actual, lower, upper, weight float64) {
+	width := upper - lower
+	score := width
+	if actual < lower {
+		score += 2 / m.alpha * (lower - actual)
+	} else if actual > upper {
+		score += 2 / m.alpha * (actual - upper)
+	}
+
+	m.weight += weight
+	if actual >= lower && actual <= upper {
+		m.covered += weight
+	}
+	m.widthSum += width * weight
+	m.scoreSum += score * weight
+}
+
+func (m *IntervalCoverage) clone() *IntervalCoverage {
+	return &IntervalCoverage{
+		alpha:    m.alpha,
+		weight:   m.weight,
+		covered:  m.covered,
+		widthSum: m.widthSum,
+		scoreSum: m.scoreSum,
+	}
+}
+
+func (m *IntervalCoverage) reset() {
+	m.weight = 0
+	m.covered = 0
+	m.widthSum = 0
+	m.scoreSum = 0
+}
+
+func (m *IntervalCoverage) snapshot() IntervalCoverageSnapshot {
+	return IntervalCoverageSnapshot{
+		Weight:    m.TotalWeight(),
+		Rate:      m.Rate(),
+		MeanWidth: m.MeanWidth(),
+		Winkler:   m.Winkler(),
+	}
+}
+
+// validateQuantile validates an observation of an actual value vs a predicted quantile.
+func validateQuantile(actual, predicted, tau, weight float64) error {
+	if !isValidProbability(tau) {
+		return ErrInvalidProbability
+	}
+	return validateNumerics(actual, predicted, weight)
+}
+
+// validateInterval validates an observation of an actual value vs a predicted interval.
+func validateInterval(actual, lower, upper, weight float64) error {
+	if !isValidNumeric(actual) || !isValidNumeric(lower) || !isValidNumeric(upper) {
+		return ErrInvalidNumeric
+	}
+	if lower > upper {
+		return ErrInvalidInterval
+	}
+	return validateWeight(weight)
+}
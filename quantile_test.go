@@ -0,0 +1,126 @@
+package mlmetrics_test
+
+import (
+	"fmt"
+	"math"
+
+	. "github.com/bsm/ginkgo"
+	. "github.com/bsm/gomega"
+	"github.com/bsm/mlmetrics"
+)
+
+var _ = Describe("QuantileLoss", func() {
+	var subject *mlmetrics.QuantileLoss
+
+	BeforeEach(func() {
+		subject = mlmetrics.NewQuantileLoss()
+		subject.Observe(10, 8, 0.9)
+		subject.Observe(10, 12, 0.9)
+		subject.Observe(10, 10, 0.5)
+		subject.Observe(5, 7, 0.1)
+		subject.ObserveWeight(4, 2, 0.5, 2.0)
+	})
+
+	It("should calculate score", func() {
+		Expect(subject.TotalWeight()).To(Equal(6.0))
+		Expect(subject.Score()).To(BeNumerically("~", 0.967, 0.001))
+		Expect(subject.BelowRate()).To(BeNumerically("~", 0.5, 0.001))
+	})
+
+	It("should ignore invalid inputs", func() {
+		subject.Observe(10, 8, 1.1)
+		subject.Observe(10, 8, -0.1)
+		subject.ObserveWeight(10, 8, 0.5, 0)
+		Expect(subject.TotalWeight()).To(Equal(6.0))
+	})
+
+	It("should handle blanks", func() {
+		subject.Reset()
+		Expect(subject.TotalWeight()).To(Equal(0.0))
+		Expect(subject.Score()).To(Equal(0.0))
+		Expect(subject.BelowRate()).To(Equal(0.0))
+	})
+
+	It("should observe batches", func() {
+		expected := mlmetrics.NewQuantileLoss()
+		expected.Observe(1, 2, 0.5)
+		expected.ObserveWeight(3, 2, 0.9, 2)
+
+		subject = mlmetrics.NewQuantileLoss()
+		Expect(subject.ObserveBatch([]float64{1, 3, 1}, []float64{2, 2, 2}, []float64{0.5, 0.9, 1.5}, []float64{1, 2, 1})).To(MatchError(mlmetrics.ErrInvalidProbability))
+		Expect(subject.Snapshot()).To(equalSnapshot(expected.Snapshot()))
+		Expect(subject.ObserveBatch([]float64{1}, []float64{2}, nil, nil)).To(MatchError(mlmetrics.ErrLengthMismatch))
+	})
+})
+
+var _ = Describe("IntervalCoverage", func() {
+	var subject *mlmetrics.IntervalCoverage
+
+	BeforeEach(func() {
+		subject = mlmetrics.NewIntervalCoverage(0.2)
+		subject.Observe(5, 4, 6)
+		subject.Observe(3, 4, 6)
+		subject.Observe(8, 4, 6)
+		subject.ObserveWeight(5, 0, 10, 2.0)
+	})
+
+	It("should calculate stats", func() {
+		Expect(subject.TotalWeight()).To(Equal(5.0))
+		Expect(subject.Rate()).To(BeNumerically("~", 0.6, 0.001))
+		Expect(subject.MeanWidth()).To(BeNumerically("~", 5.2, 0.001))
+		Expect(subject.Winkler()).To(BeNumerically("~", 11.2, 0.001))
+	})
+
+	It("should ignore inverted intervals", func() {
+		subject.Observe(5, 6, 4)
+		Expect(subject.TotalWeight()).To(Equal(5.0))
+	})
+
+	It("should handle blanks", func() {
+		subject.Reset()
+		Expect(subject.TotalWeight()).To(Equal(0.0))
+		Expect(subject.Rate()).To(Equal(0.0))
+		Expect(subject.MeanWidth()).To(Equal(0.0))
+		Expect(subject.Winkler()).To(Equal(0.0))
+	})
+
+	It("should observe batches", func() {
+		expected := mlmetrics.NewIntervalCoverage(0.2)
+		expected.Observe(1, 0, 2)
+		expected.ObserveWeight(3, 0, 2, 2)
+
+		subject = mlmetrics.NewIntervalCoverage(0.2)
+		Expect(subject.ObserveBatch([]float64{1, 3, 1}, []float64{0, 0, 2}, []float64{2, 2, 0}, []float64{1, 2, 1})).To(MatchError(mlmetrics.ErrInvalidInterval))
+		Expect(subject.Snapshot()).To(equalSnapshot(expected.Snapshot()))
+		Expect(subject.ObserveBatch([]float64{1}, []float64{0}, []float64{}, nil)).To(MatchError(mlmetrics.ErrLengthMismatch))
+	})
+
+	It("should reject invalid intervals", func() {
+		subject = mlmetrics.NewIntervalCoverage(0.2)
+		Expect(subject.ObserveWeightStrict(1, 2, 0, 1)).To(MatchError(mlmetrics.ErrInvalidInterval))
+		Expect(subject.ObserveWeightStrict(1, math.NaN(), 2, 1)).To(MatchError(mlmetrics.ErrInvalidNumeric))
+		Expect(subject.ObserveWeightStrict(1, 0, 2, 1)).To(Succeed())
+		Expect(subject.Rejected()).To(Equal(mlmetrics.Rejections{InvalidInterval: 1, InvalidNumeric: 1}))
+	})
+})
+
+func ExampleIntervalCoverage() {
+	yTrue := []float64{26, 20, 24, 21, 23, 25, 27}
+	yP10 := []float64{22, 21, 20, 20, 21, 25, 24}
+	yP90 := []float64{28, 27, 25, 24, 26, 31, 30}
+
+	metric := mlmetrics.NewIntervalCoverage(0.2)
+	for i := range yTrue {
+		metric.Observe(yTrue[i], yP10[i], yP90[i])
+	}
+
+	// print score
+	fmt.Printf("coverage : %.3f\n", metric.Rate())
+	fmt.Printf("width    : %.3f\n", metric.MeanWidth())
+	fmt.Printf("winkler  : %.3f\n", metric.Winkler())
+
+	// Output:
+	// coverage : 0.857
+	// width    : 5.429
+	// winkler  : 6.857
+}
@@ -0,0 +1,360 @@
+package mlmetrics
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// Ranking evaluates ranked result lists, e.g. of search queries or recommendations.
+// Each observation is a single query with the graded relevance of its results in
+// ranked order. Results with a relevance greater than zero are considered relevant.
+// All scores are averaged across the observed queries.
+type Ranking struct {
+	rejector
+
+	k int
+
+	weight    float64 // total weight of queries observed
+	ndcgSum   float64 // weighted sum of NDCG scores
+	apSum     float64 // weighted sum of average precision scores
+	rrSum     float64 // weighted sum of reciprocal ranks
+	precSum   float64 // weighted sum of precision scores
+	recallSum float64 // weighted sum of recall scores
+	hitSum    float64 // weighted sum of hits
+
+	mu sync.RWMutex
+}
+
+// RankingSnapshot is a point-in-time snapshot of a Ranking metric.
+type RankingSnapshot struct {
+	K         int        // cut-off rank
+	Weight    float64    // total weight of queries observed
+	NDCG      float64    // normalized discounted cumulative gain
+	MAP       float64    // mean average precision
+	MRR       float64    // mean reciprocal rank
+	Precision float64    // mean precision
+	Recall    float64    // mean recall
+	HitRate   float64    // rate of queries with at least one relevant result
+	Rejected  Rejections // observations rejected by validation
+}
+
+// NewRanking inits a new metric which evaluates the top k results of each query.
+// A k of 0 evaluates complete result lists.
+func NewRanking(k int) *Ranking {
+	if k < 0 {
+		k = 0
+	}
+	return &Ranking{k: k}
+}
+
+// K returns the cut-off rank.
+func (m *Ranking) K() int {
+	return m.k
+}
+
+// Reset resets state.
+func (m *Ranking) Reset() {
+	m.mu.Lock()
+	m.reset()
+	m.mu.Unlock()
+	m.resetRejected()
+}
+
+// Observe records the relevance of ranked results of a query. It assumes that the
+// results include all relevant items, use ObserveQuery otherwise.
+// Assuming a query returned the following results:
+//
+//	#1 doc-7 (relevance: 3)
+//	#2 doc-2 (relevance: 0)
+//	#3 doc-4 (relevance: 1)
+//
+// Then the recorded values should be:
+//
+//	m.Observe([]float64{3, 0, 1})
+func (m *Ranking) Observe(relevance []float64) {
+	m.ObserveQuery(relevance, relevance, 1.0)
+}
+
+// ObserveWeight records the relevance of ranked results of a query with a given weight.
+func (m *Ranking) ObserveWeight(relevance []float64, weight float64) {
+	m.ObserveQuery(relevance, relevance, weight)
+}
+
+// ObserveQuery records the relevance of ranked results of a query along with the
+// relevance grades of all known items for the query (in any order), including
+// items which were not part of the results. The latter are used to determine the
+// ideal ranking and the total number of relevant items. Queries with more relevant
+// results than relevant items in ideal are rejected with ErrLengthMismatch, as
+// their recall, NDCG and average precision would exceed 1.
+func (m *Ranking) ObserveQuery(relevance, ideal []float64, weight float64) {
+	_ = m.ObserveQueryStrict(relevance, ideal, weight)
+}
+
+// ObserveQueryStrict is like ObserveQuery, but returns an error if the observation was
+// rejected by validation, see Rejected.
+func (m *Ranking) ObserveQueryStrict(relevance, ideal []float64, weight float64) error {
+	if err := validateRanking(relevance, ideal, weight); err != nil {
+		return m.reject(err)
+	}
+
+	scores := m.score(relevance, ideal)
+
+	m.mu.Lock()
+	m.observe(scores, weight)
+	m.mu.Unlock()
+	return nil
+}
+
+// ObserveBatch records the relevance of ranked results of a batch of queries, see
+// Observe. Weights are optional, if given, they must match the number of observations.
+// Returns ErrLengthMismatch if the lengths of the inputs differ and a *BatchError
+// if observations were rejected.
+func (m *Ranking) ObserveBatch(relevance [][]float64, weights []float64) error {
+	if err := validateBatch(len(relevance), weights); err != nil {
+		return m.rejectBatch(len(relevance), err)
+	}
+
+	// score queries before locking
+	var berr BatchError
+	scores := make([]rankingScores, 0, len(relevance))
+	scoreWeights := make([]float64, 0, len(relevance))
+	for i, rel := range relevance {
+		weight := batchWeight(weights, i)
+		if err := validateRanking(rel, rel, weight); err != nil {
+			berr.add(i, m.reject(err))
+			continue
+		}
+		scores = append(scores, m.score(rel, rel))
+		scoreWeights = append(scoreWeights, weight)
+	}
+
+	m.mu.Lock()
+	for i, s := range scores {
+		m.observe(s, scoreWeights[i])
+	}
+	m.mu.Unlock()
+	return berr.errOrNil()
+}
+
+// TotalWeight returns the total weight of queries observed.
+func (m *Ranking) TotalWeight() float64 {
+	m.mu.RLock()
+	weight := m.weight
+	m.mu.RUnlock()
+	return weight
+}
+
+// NDCG calculates the normalized discounted cumulative gain at k.
+// https://en.wikipedia.org/wiki/Discounted_cumulative_gain
+func (m *Ranking) NDCG() float64 { return m.mean(&m.ndcgSum) }
+
+// MAP calculates the mean average precision at k.
+// https://en.wikipedia.org/wiki/Evaluation_measures_(information_retrieval)#Mean_average_precision
+func (m *Ranking) MAP() float64 { return m.mean(&m.apSum) }
+
+// MRR calculates the mean reciprocal rank of the first relevant result within k.
+// https://en.wikipedia.org/wiki/Mean_reciprocal_rank
+func (m *Ranking) MRR() float64 { return m.mean(&m.rrSum) }
+
+// Precision calculates the mean precision at k, the fraction of the top k results
+// which are relevant.
+func (m *Ranking) Precision() float64 { return m.mean(&m.precSum) }
+
+// Recall calculates the mean recall at k, the fraction of relevant items which are
+// included in the top k results.
+func (m *Ranking) Recall() float64 { return m.mean(&m.recallSum) }
+
+// HitRate calculates the rate of queries with at least one relevant result within k.
+func (m *Ranking) HitRate() float64 { return m.mean(&m.hitSum) }
+
+func (m *Ranking) mean(sum *float64) float64 {
+	m.mu.RLock()
+	weight := m.weight
+	value := *sum
+	m.mu.RUnlock()
+
+	if weight > 0 {
+		return value / weight
+	}
+	return 0.0
+}
+
+// Snapshot returns a consistent point-in-time snapshot of all values.
+func (m *Ranking) Snapshot() RankingSnapshot {
+	m.mu.RLock()
+	c := m.clone()
+	m.mu.RUnlock()
+
+	snap := c.snapshot()
+	snap.Rejected = m.Rejected()
+	return snap
+}
+
+// SnapshotAndReset atomically returns a snapshot of all values and resets state.
+func (m *Ranking) SnapshotAndReset() RankingSnapshot {
+	m.mu.Lock()
+	c := m.clone()
+	m.reset()
+	m.mu.Unlock()
+
+	snap := c.snapshot()
+	snap.Rejected = m.harvestRejected()
+	return snap
+}
+
+// observe records the scores of a query, the caller must hold the lock.
+func (m *Ranking) observe(s rankingScores, weight float64) {
+	m.weight += weight
+	m.ndcgSum += s.ndcg * weight
+	m.apSum += s.ap * weight
+	m.rrSum += s.rr * weight
+	m.precSum += s.prec * weight
+	m.recallSum += s.recall * weight
+	m.hitSum += s.hit * weight
+}
+
+// score calculates the scores of a single query.
+func (m *Ranking) score(relevance, ideal []float64) rankingScores {
+	var numRelevant int
+	for _, r := range ideal {
+		if r > 0 {
+			numRelevant++
+		}
+	}
+
+	k := len(relevance)
+	if m.k != 0 && m.k < k {
+		k = m.k
+	}
+	top := relevance[:k]
+
+	var dcg, apSum, rr float64
+	var hits int
+	for i, r := range top {
+		if r <= 0 {
+			continue
+		}
+
+		dcg += r / math.Log2(float64(i+2))
+		hits++
+		apSum += float64(hits) / float64(i+1)
+		if rr == 0 {
+			rr = 1 / float64(i+1)
+		}
+	}
+
+	var ndcg, ap, prec, recall, hit float64
+	if idcg := idealDCG(ideal, m.k); idcg > 0 {
+		ndcg = dcg / idcg
+	}
+	if numRelevant > 0 {
+		recall = float64(hits) / float64(numRelevant)
+		if m.k != 0 && m.k < numRelevant {
+			ap = apSum / float64(m.k)
+		} else {
+			ap = apSum / float64(numRelevant)
+		}
+	}
+	if n := m.k; n != 0 {
+		prec = float64(hits) / float64(n)
+	} else if n := len(relevance); n != 0 {
+		prec = float64(hits) / float64(n)
+	}
+	if hits > 0 {
+		hit = 1
+	}
+
+	return rankingScores{ndcg: ndcg, ap: ap, rr: rr, prec: prec, recall: recall, hit: hit}
+}
+
+func (m *Ranking) clone() *Ranking {
+	return &Ranking{
+		k:         m.k,
+		weight:    m.weight,
+		ndcgSum:   m.ndcgSum,
+		apSum:     m.apSum,
+		rrSum:     m.rrSum,
+		precSum:   m.precSum,
+		recallSum: m.recallSum,
+		hitSum:    m.hitSum,
+	}
+}
+
+func (m *Ranking) reset() {
+	m.weight = 0
+	m.ndcgSum = 0
+	m.apSum = 0
+	m.rrSum = 0
+	m.precSum = 0
+	m.recallSum = 0
+	m.hitSum = 0
+}
+
+func (m *Ranking) snapshot() RankingSnapshot {
+	return RankingSnapshot{
+		K:         m.K(),
+		Weight:    m.TotalWeight(),
+		NDCG:      m.NDCG(),
+		MAP:       m.MAP(),
+		MRR:       m.MRR(),
+		Precision: m.Precision(),
+		Recall:    m.Recall(),
+		HitRate:   m.HitRate(),
+	}
+}
+
+func idealDCG(ideal []float64, k int) float64 {
+	grades := make([]float64, 0, len(ideal))
+	for _, r := range ideal {
+		if r > 0 {
+			grades = append(grades, r)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(grades)))
+
+	if k != 0 && k < len(grades) {
+		grades = grades[:k]
+	}
+
+	var dcg float64
+	for i, r := range grades {
+		dcg += r / math.Log2(float64(i+2))
+	}
+	return dcg
+}
+
+// rankingScores are the scores of a single query.
+type rankingScores struct {
+	ndcg, ap, rr, prec, recall, hit float64
+}
+
+// validateRanking validates the relevance grades of ranked results of a query
+// against the grades of all known items.
+func validateRanking(relevance, ideal []float64, weight float64) error {
+	numRelevant, err := countRelevant(relevance)
+	if err != nil {
+		return err
+	}
+	numIdeal, err := countRelevant(ideal)
+	if err != nil {
+		return err
+	}
+	if numRelevant > numIdeal {
+		return ErrLengthMismatch
+	}
+	return validateWeight(weight)
+}
+
+// countRelevant counts the relevant items of a list of finite relevance grades.
+func countRelevant(grades []float64) (n int, err error) {
+	for _, r := range grades {
+		if !isFinite(r) {
+			return 0, ErrInvalidNumeric
+		}
+		if r > 0 {
+			n++
+		}
+	}
+	return n, nil
+}
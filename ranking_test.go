@@ -0,0 +1,120 @@
+package mlmetrics_test
+
+import (
+	"fmt"
+	"math"
+
+	. "github.com/bsm/ginkgo"
+	. "github.com/bsm/gomega"
+	"github.com/bsm/mlmetrics"
+)
+
+var _ = Describe("Ranking", func() {
+	var subject *mlmetrics.Ranking
+
+	BeforeEach(func() {
+		subject = mlmetrics.NewRanking(3)
+		subject.Observe([]float64{3, 0, 1, 2})
+		subject.Observe([]float64{0, 0, 1})
+		subject.Observe([]float64{0, 0, 0, 1})
+	})
+
+	It("should calculate scores", func() {
+		Expect(subject.K()).To(Equal(3))
+		Expect(subject.TotalWeight()).To(Equal(3.0))
+		Expect(subject.NDCG()).To(BeNumerically("~", 0.412, 0.001))
+		Expect(subject.MAP()).To(BeNumerically("~", 0.296, 0.001))
+		Expect(subject.MRR()).To(BeNumerically("~", 0.444, 0.001))
+		Expect(subject.Precision()).To(BeNumerically("~", 0.333, 0.001))
+		Expect(subject.Recall()).To(BeNumerically("~", 0.556, 0.001))
+		Expect(subject.HitRate()).To(BeNumerically("~", 0.667, 0.001))
+	})
+
+	It("should calculate weighted scores", func() {
+		subject.ObserveWeight([]float64{1, 1, 1}, 3.0)
+		Expect(subject.TotalWeight()).To(Equal(6.0))
+		Expect(subject.NDCG()).To(BeNumerically("~", 0.706, 0.001))
+		Expect(subject.HitRate()).To(BeNumerically("~", 0.833, 0.001))
+	})
+
+	It("should consider relevant items missing from results", func() {
+		subject.Reset()
+		subject.ObserveQuery([]float64{1, 0, 0}, []float64{1, 2, 0, 0}, 1.0)
+		Expect(subject.NDCG()).To(BeNumerically("~", 0.380, 0.001))
+		Expect(subject.MAP()).To(BeNumerically("~", 0.5, 0.001))
+		Expect(subject.Recall()).To(BeNumerically("~", 0.5, 0.001))
+	})
+
+	It("should reject invalid queries", func() {
+		subject.Reset()
+		Expect(subject.ObserveQueryStrict([]float64{1, math.Inf(1)}, []float64{1, 2}, 1.0)).To(MatchError(mlmetrics.ErrInvalidNumeric))
+		Expect(subject.ObserveQueryStrict([]float64{1, 0}, []float64{1, math.NaN()}, 1.0)).To(MatchError(mlmetrics.ErrInvalidNumeric))
+		Expect(subject.ObserveQueryStrict([]float64{1, 0}, []float64{1, math.Inf(1)}, 1.0)).To(MatchError(mlmetrics.ErrInvalidNumeric))
+		Expect(subject.ObserveQueryStrict([]float64{1, 2}, []float64{2, 0}, 1.0)).To(MatchError(mlmetrics.ErrLengthMismatch))
+		Expect(subject.ObserveQueryStrict([]float64{1, 2}, []float64{3, 1, 2}, 0)).To(MatchError(mlmetrics.ErrInvalidWeight))
+		Expect(subject.TotalWeight()).To(Equal(0.0))
+		Expect(subject.Rejected()).To(Equal(mlmetrics.Rejections{InvalidNumeric: 3, LengthMismatch: 1, InvalidWeight: 1}))
+
+		Expect(subject.ObserveQueryStrict([]float64{1, 2}, []float64{3, 1, 2}, 1.0)).To(Succeed())
+		Expect(subject.Recall()).To(BeNumerically("~", 0.667, 0.001))
+	})
+
+	It("should evaluate complete lists", func() {
+		subject = mlmetrics.NewRanking(0)
+		subject.Observe([]float64{0, 0, 0, 1})
+		Expect(subject.MRR()).To(BeNumerically("~", 0.25, 0.001))
+		Expect(subject.Precision()).To(BeNumerically("~", 0.25, 0.001))
+		Expect(subject.Recall()).To(BeNumerically("~", 1.0, 0.001))
+	})
+
+	It("should handle blanks", func() {
+		subject.Reset()
+		Expect(subject.TotalWeight()).To(Equal(0.0))
+		Expect(subject.NDCG()).To(Equal(0.0))
+		Expect(subject.MAP()).To(Equal(0.0))
+		Expect(subject.MRR()).To(Equal(0.0))
+
+		subject.Observe(nil)
+		Expect(subject.TotalWeight()).To(Equal(1.0))
+		Expect(subject.NDCG()).To(Equal(0.0))
+	})
+
+	It("should observe batches", func() {
+		expected := mlmetrics.NewRanking(3)
+		expected.Observe([]float64{3, 0, 1})
+		expected.ObserveWeight([]float64{0, 0, 2}, 2)
+
+		subject = mlmetrics.NewRanking(3)
+		Expect(subject.ObserveBatch([][]float64{{3, 0, 1}, {0, 0, 2}, {math.NaN()}}, []float64{1, 2, 1})).To(MatchError(mlmetrics.ErrInvalidNumeric))
+		Expect(subject.Snapshot()).To(equalSnapshot(expected.Snapshot()))
+		Expect(subject.ObserveBatch([][]float64{{1}}, []float64{})).To(MatchError(mlmetrics.ErrLengthMismatch))
+	})
+})
+
+func ExampleRanking() {
+	// relevance grades of the top results of three search queries
+	queries := [][]float64{
+		{3, 2, 0, 0, 1},
+		{0, 1, 0, 2, 0},
+		{0, 0, 0, 0, 0},
+	}
+
+	metric := mlmetrics.NewRanking(3)
+	for _, relevance := range queries {
+		metric.Observe(relevance)
+	}
+
+	// print scores
+	fmt.Printf("ndcg@3      : %.3f\n", metric.NDCG())
+	fmt.Printf("map@3       : %.3f\n", metric.MAP())
+	fmt.Printf("mrr         : %.3f\n", metric.MRR())
+	fmt.Printf("precision@3 : %.3f\n", metric.Precision())
+	fmt.Printf("recall@3    : %.3f\n", metric.Recall())
+
+	// Output:
+	// ndcg@3      : 0.378
+	// map@3       : 0.306
+	// mrr         : 0.500
+	// precision@3 : 0.333
+	// recall@3    : 0.389
+}
@@ -0,0 +1,368 @@
+package mlmetrics
+
+import (
+	"math"
+	"sync"
+)
+
+// RecommendationOptions configure the Recommendations metric.
+type RecommendationOptions struct {
+	// CatalogSize is the total number of items in the catalog.
+	// Required for Coverage.
+	CatalogSize int
+
+	// Popularity returns the probability that a user has interacted with an
+	// item, e.g. the share of users in the training data. Required for Novelty.
+	Popularity func(item int) float64
+
+	// Similarity returns the similarity of two items between 0 and 1.
+	// Required for Diversity.
+	Similarity func(a, b int) float64
+}
+
+// Recommendations evaluates the output of recommender systems beyond accuracy. It
+// measures how much of the catalog is recommended, how novel and how diverse
+// recommendations are and how personalized they are across users.
+// Items are identified by their non-negative index.
+type Recommendations struct {
+	rejector
+
+	opt RecommendationOptions
+
+	weight       float64 // total weight of users observed
+	weight2      float64 // sum of squared user weights
+	noveltySum   float64 // weighted sum of novelty scores
+	noveltyW     float64 // total weight of users with novelty scores
+	diversitySum float64 // weighted sum of intra-list diversity scores
+	diversityW   float64 // total weight of users with diversity scores
+	items        map[int]*recommendedItem
+
+	mu sync.RWMutex
+}
+
+// RecommendationsSnapshot is a point-in-time snapshot of a Recommendations metric.
+type RecommendationsSnapshot struct {
+	Weight          float64    // total weight of users observed
+	NumItems        int        // number of distinct items recommended
+	Coverage        float64    // catalog coverage
+	Novelty         float64    // mean novelty
+	Diversity       float64    // mean intra-list diversity
+	Personalization float64    // personalization
+	Rejected        Rejections // observations rejected by validation
+}
+
+type recommendedItem struct {
+	sum  float64 // sum of w/sqrt(n) across users
+	sum2 float64 // sum of (w/sqrt(n))² across users
+}
+
+// NewRecommendations inits a new metric.
+func NewRecommendations(opt *RecommendationOptions) *Recommendations {
+	m := &Recommendations{items: make(map[int]*recommendedItem)}
+	if opt != nil {
+		m.opt = *opt
+	}
+	return m
+}
+
+// Reset resets state.
+func (m *Recommendations) Reset() {
+	m.mu.Lock()
+	m.reset()
+	m.mu.Unlock()
+	m.resetRejected()
+}
+
+// Observe records the list of items recommended to a single user.
+func (m *Recommendations) Observe(items []int) {
+	m.ObserveWeight(items, 1.0)
+}
+
+// ObserveWeight records the list of items recommended to a single user with a given weight.
+// Empty lists are ignored.
+func (m *Recommendations) ObserveWeight(items []int, weight float64) {
+	_ = m.ObserveWeightStrict(items, weight)
+}
+
+// ObserveWeightStrict is like ObserveWeight, but returns an error if the observation was
+// rejected by validation, see Rejected.
+func (m *Recommendations) ObserveWeightStrict(items []int, weight float64) error {
+	if err := validateRecommendation(items, weight); err != nil {
+		return m.reject(err)
+	} else if len(items) == 0 {
+		return nil
+	}
+
+	rec := m.recommendation(items)
+
+	m.mu.Lock()
+	m.observe(rec, weight)
+	m.mu.Unlock()
+	return nil
+}
+
+// ObserveBatch records the lists of items recommended to a batch of users.
+// Weights are optional, if given, they must match the number of observations.
+// Returns ErrLengthMismatch if the lengths of the inputs differ and a *BatchError
+// if observations were rejected.
+func (m *Recommendations) ObserveBatch(items [][]int, weights []float64) error {
+	if err := validateBatch(len(items), weights); err != nil {
+		return m.rejectBatch(len(items), err)
+	}
+
+	// calculate recommendations before locking, callbacks may be slow
+	var berr BatchError
+	recs := make([]recommendation, 0, len(items))
+	recWeights := make([]float64, 0, len(items))
+	for i := range items {
+		weight := batchWeight(weights, i)
+		if err := validateRecommendation(items[i], weight); err != nil {
+			berr.add(i, m.reject(err))
+			continue
+		}
+		if len(items[i]) != 0 {
+			recs = append(recs, m.recommendation(items[i]))
+			recWeights = append(recWeights, weight)
+		}
+	}
+
+	m.mu.Lock()
+	for i, rec := range recs {
+		m.observe(rec, recWeights[i])
+	}
+	m.mu.Unlock()
+	return berr.errOrNil()
+}
+
+// TotalWeight returns the total weight of users observed.
+func (m *Recommendations) TotalWeight() float64 {
+	m.mu.RLock()
+	weight := m.weight
+	m.mu.RUnlock()
+	return weight
+}
+
+// NumItems returns the number of distinct items recommended.
+func (m *Recommendations) NumItems() int {
+	m.mu.RLock()
+	n := len(m.items)
+	m.mu.RUnlock()
+	return n
+}
+
+// Coverage returns the share of catalog items that were recommended at least once.
+func (m *Recommendations) Coverage() float64 {
+	if m.opt.CatalogSize <= 0 {
+		return 0.0
+	}
+
+	n := float64(m.NumItems())
+	if size := float64(m.opt.CatalogSize); n < size {
+		return n / size
+	}
+	return 1.0
+}
+
+// Novelty returns the mean self-information (-log2(popularity)) of recommended
+// items. Recommending less popular items increases novelty.
+func (m *Recommendations) Novelty() float64 {
+	m.mu.RLock()
+	weight := m.noveltyW
+	sum := m.noveltySum
+	m.mu.RUnlock()
+
+	if weight > 0 {
+		return sum / weight
+	}
+	return 0.0
+}
+
+// Diversity returns the mean intra-list diversity, i.e. the mean dissimilarity
+// (1 - similarity) between all pairs of items recommended to the same user.
+func (m *Recommendations) Diversity() float64 {
+	m.mu.RLock()
+	weight := m.diversityW
+	sum := m.diversitySum
+	m.mu.RUnlock()
+
+	if weight > 0 {
+		return sum / weight
+	}
+	return 0.0
+}
+
+// Personalization returns the mean dissimilarity (1 - cosine similarity) between
+// the recommendation lists of all pairs of users. A value close to 0 indicates
+// that all users receive the same recommendations. Pairs are weighted by the
+// product of both users' weights.
+func (m *Recommendations) Personalization() float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	pairs := (m.weight*m.weight - m.weight2) / 2
+	if pairs <= 0 {
+		return 0.0
+	}
+
+	var sim float64
+	for _, item := range m.items {
+		sim += (item.sum*item.sum - item.sum2) / 2
+	}
+	return 1 - sim/pairs
+}
+
+// Snapshot returns a consistent point-in-time snapshot of all values.
+func (m *Recommendations) Snapshot() RecommendationsSnapshot {
+	m.mu.RLock()
+	c := m.clone()
+	m.mu.RUnlock()
+
+	snap := c.snapshot()
+	snap.Rejected = m.Rejected()
+	return snap
+}
+
+// SnapshotAndReset atomically returns a snapshot of all values and resets state.
+func (m *Recommendations) SnapshotAndReset() RecommendationsSnapshot {
+	m.mu.Lock()
+	c := m.clone()
+	m.reset()
+	m.mu.Unlock()
+
+	snap := c.snapshot()
+	snap.Rejected = m.harvestRejected()
+	return snap
+}
+
+// observe records a recommendation, the caller must hold the lock.
+func (m *Recommendations) observe(rec recommendation, weight float64) {
+	norm := weight / math.Sqrt(float64(len(rec.items)))
+
+	m.weight += weight
+	m.weight2 += weight * weight
+	if rec.hasNovelty {
+		m.noveltySum += rec.novelty * weight
+		m.noveltyW += weight
+	}
+	if rec.hasDiversity {
+		m.diversitySum += rec.diversity * weight
+		m.diversityW += weight
+	}
+	for _, x := range rec.items {
+		item, ok := m.items[x]
+		if !ok {
+			item = new(recommendedItem)
+			m.items[x] = item
+		}
+		item.sum += norm
+		item.sum2 += norm * norm
+	}
+}
+
+// recommendation prepares a non-empty list of recommended items for recording.
+func (m *Recommendations) recommendation(items []int) recommendation {
+	// remove duplicates
+	seen := make(map[int]struct{}, len(items))
+	uniq := make([]int, 0, len(items))
+	for _, x := range items {
+		if _, ok := seen[x]; !ok {
+			seen[x] = struct{}{}
+			uniq = append(uniq, x)
+		}
+	}
+
+	rec := recommendation{items: uniq}
+	rec.novelty, rec.hasNovelty = m.novelty(uniq)
+	rec.diversity, rec.hasDiversity = m.diversity(uniq)
+	return rec
+}
+
+func (m *Recommendations) clone() *Recommendations {
+	c := &Recommendations{
+		opt:          m.opt,
+		weight:       m.weight,
+		weight2:      m.weight2,
+		noveltySum:   m.noveltySum,
+		noveltyW:     m.noveltyW,
+		diversitySum: m.diversitySum,
+		diversityW:   m.diversityW,
+		items:        make(map[int]*recommendedItem, len(m.items)),
+	}
+	for x, item := range m.items {
+		cp := *item
+		c.items[x] = &cp
+	}
+	return c
+}
+
+func (m *Recommendations) reset() {
+	m.weight = 0
+	m.weight2 = 0
+	m.noveltySum = 0
+	m.noveltyW = 0
+	m.diversitySum = 0
+	m.diversityW = 0
+	m.items = make(map[int]*recommendedItem)
+}
+
+func (m *Recommendations) snapshot() RecommendationsSnapshot {
+	return RecommendationsSnapshot{
+		Weight:          m.TotalWeight(),
+		NumItems:        m.NumItems(),
+		Coverage:        m.Coverage(),
+		Novelty:         m.Novelty(),
+		Diversity:       m.Diversity(),
+		Personalization: m.Personalization(),
+	}
+}
+
+func (m *Recommendations) novelty(items []int) (float64, bool) {
+	if m.opt.Popularity == nil {
+		return 0, false
+	}
+
+	var sum float64
+	var n int
+	for _, x := range items {
+		if p := m.opt.Popularity(x); p > 0 && p <= 1 {
+			sum -= math.Log2(p)
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return sum / float64(n), true
+}
+
+func (m *Recommendations) diversity(items []int) (float64, bool) {
+	if m.opt.Similarity == nil || len(items) < 2 {
+		return 0, false
+	}
+
+	var sum float64
+	for i := 0; i < len(items); i++ {
+		for j := i + 1; j < len(items); j++ {
+			sum += 1 - m.opt.Similarity(items[i], items[j])
+		}
+	}
+	n := len(items) * (len(items) - 1) / 2
+	return sum / float64(n), true
+}
+
+// recommendation is a list of unique recommended items along with its scores.
+type recommendation struct {
+	items                    []int
+	novelty, diversity       float64
+	hasNovelty, hasDiversity bool
+}
+
+// validateRecommendation validates a list of recommended items.
+func validateRecommendation(items []int, weight float64) error {
+	for _, x := range items {
+		if !isValidCategory(x) {
+			return ErrInvalidCategory
+		}
+	}
+	return validateWeight(weight)
+}
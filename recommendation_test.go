@@ -0,0 +1,95 @@
+package mlmetrics_test
+
+import (
+	. "github.com/bsm/ginkgo"
+	. "github.com/bsm/gomega"
+	"github.com/bsm/mlmetrics"
+)
+
+var _ = Describe("Recommendations", func() {
+	var subject *mlmetrics.Recommendations
+
+	BeforeEach(func() {
+		subject = mlmetrics.NewRecommendations(&mlmetrics.RecommendationOptions{
+			CatalogSize: 10,
+			Popularity:  func(item int) float64 { return 1 / float64(item+1) },
+			Similarity: func(a, b int) float64 {
+				if a-b == 1 || b-a == 1 {
+					return 1
+				}
+				return 0
+			},
+		})
+		subject.Observe([]int{0, 1, 2})
+		subject.Observe([]int{0, 1, 3})
+		subject.Observe([]int{4, 5, 6})
+	})
+
+	It("should calculate scores", func() {
+		Expect(subject.TotalWeight()).To(Equal(3.0))
+		Expect(subject.NumItems()).To(Equal(7))
+		Expect(subject.Coverage()).To(BeNumerically("~", 0.7, 0.001))
+		Expect(subject.Novelty()).To(BeNumerically("~", 1.478, 0.001))
+		Expect(subject.Diversity()).To(BeNumerically("~", 0.444, 0.001))
+		Expect(subject.Personalization()).To(BeNumerically("~", 0.778, 0.001))
+	})
+
+	It("should calculate weighted scores", func() {
+		subject.ObserveWeight([]int{4, 5, 6}, 2.0)
+		Expect(subject.TotalWeight()).To(Equal(5.0))
+		Expect(subject.Novelty()).To(BeNumerically("~", 1.915, 0.001))
+		Expect(subject.Diversity()).To(BeNumerically("~", 0.400, 0.001))
+		Expect(subject.Personalization()).To(BeNumerically("~", 0.704, 0.001))
+	})
+
+	It("should handle identical recommendations", func() {
+		subject.Reset()
+		subject.Observe([]int{1, 2, 2, 3})
+		subject.Observe([]int{3, 2, 1})
+		Expect(subject.Personalization()).To(BeNumerically("~", 0.0, 0.001))
+	})
+
+	It("should ignore invalid items", func() {
+		subject.Observe([]int{1, -1})
+		subject.Observe(nil)
+		Expect(subject.TotalWeight()).To(Equal(3.0))
+	})
+
+	It("should handle missing options", func() {
+		subject = mlmetrics.NewRecommendations(nil)
+		subject.Observe([]int{0, 1, 2})
+		Expect(subject.Coverage()).To(Equal(0.0))
+		Expect(subject.Novelty()).To(Equal(0.0))
+		Expect(subject.Diversity()).To(Equal(0.0))
+		Expect(subject.Personalization()).To(Equal(0.0))
+	})
+
+	It("should handle blanks", func() {
+		subject.Reset()
+		Expect(subject.TotalWeight()).To(Equal(0.0))
+		Expect(subject.NumItems()).To(Equal(0))
+		Expect(subject.Coverage()).To(Equal(0.0))
+		Expect(subject.Novelty()).To(Equal(0.0))
+		Expect(subject.Diversity()).To(Equal(0.0))
+		Expect(subject.Personalization()).To(Equal(0.0))
+	})
+
+	It("should observe batches", func() {
+		expected := mlmetrics.NewRecommendations(nil)
+		expected.Observe([]int{0, 1, 2})
+		expected.ObserveWeight([]int{1, 3}, 2)
+
+		subject = mlmetrics.NewRecommendations(nil)
+		Expect(subject.ObserveBatch([][]int{{0, 1, 2}, {1, 3}, {}, {-1}}, []float64{1, 2, 1, 1})).To(MatchError(mlmetrics.ErrInvalidCategory))
+		Expect(subject.Snapshot()).To(equalSnapshot(expected.Snapshot()))
+		Expect(subject.ObserveBatch([][]int{{1}}, []float64{})).To(MatchError(mlmetrics.ErrLengthMismatch))
+	})
+
+	It("should reject invalid items", func() {
+		subject = mlmetrics.NewRecommendations(nil)
+		Expect(subject.ObserveWeightStrict([]int{1, -1}, 1)).To(MatchError(mlmetrics.ErrInvalidCategory))
+		Expect(subject.ObserveWeightStrict([]int{}, 1)).To(Succeed())
+		Expect(subject.TotalWeight()).To(BeZero())
+		Expect(subject.Rejected()).To(Equal(mlmetrics.Rejections{InvalidCategory: 1}))
+	})
+})
@@ -2,21 +2,37 @@ package mlmetrics
 
 import (
 	"math"
-	"sync"
+	"sort"
 )
 
 // Regression is a basic regression evaluator
 type Regression struct {
-	weight float64 // total weight observed
-	sum    float64 // sum of all values
+	rejector
+	buckets []float64 // histogram bucket upper bounds
+	shards  shards[regressionState]
+}
 
-	resSum   float64 // residual sum
-	resSum2  float64 // residual sum of squares
-	logSum2  float64 // logarithmic residual sum of squares
-	totSum2  float64 // total sum of squares
-	maxDelta float64 // maximum error delta
+// RegressionSnapshot is a point-in-time snapshot of a Regression metric.
+type RegressionSnapshot struct {
+	Weight   float64 // total weight observed
+	Mean     float64 // mean actual value
+	MAE      float64 // mean absolute error
+	MSE      float64 // mean squared error
+	RMSE     float64 // root mean squared error
+	MSLE     float64 // mean squared logarithmic error
+	RMSLE    float64 // root mean squared logarithmic error
+	R2       float64 // coefficient of determination
+	MaxError float64 // maximum error delta
+
+	ResidualMean     float64 // mean of signed residuals
+	ResidualVariance float64 // variance of signed residuals
+	ResidualSkewness float64 // skewness of signed residuals
+	ResidualKurtosis float64 // excess kurtosis of signed residuals
+
+	HistogramBuckets []float64 // histogram bucket upper bounds, if enabled
+	HistogramWeights []float64 // histogram weights, if enabled
 
-	mu sync.RWMutex
+	Rejected Rejections // observations rejected by validation
 }
 
 // NewRegression inits a new metric.
@@ -24,17 +40,27 @@ func NewRegression() *Regression {
 	return &Regression{}
 }
 
+// NewRegressionWithHistogram inits a new metric which additionally records a
+// histogram of signed residuals (actual - predicted). Buckets are the inclusive
+// upper bounds of each histogram bucket, an additional bucket collects all
+// residuals above the highest bound. See LinearBuckets and ExponentialBuckets.
+func NewRegressionWithHistogram(buckets []float64) *Regression {
+	bounds := make([]float64, 0, len(buckets))
+	for _, b := range buckets {
+		if isValidNumeric(b) {
+			bounds = append(bounds, b)
+		}
+	}
+	sort.Float64s(bounds)
+	bounds = uniqueFloat64s(bounds)
+
+	return &Regression{buckets: bounds}
+}
+
 // Reset resets state.
 func (m *Regression) Reset() {
-	m.mu.Lock()
-	m.weight = 0
-	m.sum = 0
-	m.resSum = 0
-	m.resSum2 = 0
-	m.logSum2 = 0
-	m.totSum2 = 0
-	m.maxDelta = 0
-	m.mu.Unlock()
+	m.shards.each(func(s *regressionState) { *s = regressionState{} })
+	m.resetRejected()
 }
 
 // Observe records an observation of the actual vs the predicted value.
@@ -44,99 +70,80 @@ func (m *Regression) Observe(actual, predicted float64) {
 
 // ObserveWeight records an observation of the actual vs the predicted value with a given weight.
 func (m *Regression) ObserveWeight(actual, predicted, weight float64) {
-	if !isValidNumeric(actual) || !isValidNumeric(predicted) || !isValidWeight(weight) {
-		return
+	_ = m.ObserveWeightStrict(actual, predicted, weight)
+}
+
+// ObserveWeightStrict is like ObserveWeight, but returns an error if the observation was
+// rejected by validation, see Rejected.
+func (m *Regression) ObserveWeightStrict(actual, predicted, weight float64) error {
+	if err := validateNumerics(actual, predicted, weight); err != nil {
+		return m.reject(err)
 	}
 
-	residual := math.Abs(actual - predicted)
-	logres := math.Abs(math.Log1p(actual) - math.Log1p(predicted))
+	sh := m.shards.acquire()
+	m.observe(&sh.state, actual, predicted, weight)
+	m.shards.release(sh)
+	return nil
+}
 
-	m.mu.Lock()
-	defer m.mu.Unlock()
+// ObserveBatch records a batch of observations of actual vs predicted values.
+// Weights are optional, if given, they must match the number of observations.
+// Returns ErrLengthMismatch if the lengths of the inputs differ and a *BatchError
+// if observations were rejected.
+func (m *Regression) ObserveBatch(actual, predicted []float64, weights []float64) error {
+	return ObserveRegressionBatch(m, actual, predicted, weights)
+}
 
-	if residual > m.maxDelta {
-		m.maxDelta = residual
-	}
-	if m.weight != 0 {
-		delta := actual - m.sum/m.weight
-		m.totSum2 += delta * delta * weight
+// ObserveRegressionBatch records a batch of observations of actual vs predicted values
+// of any floating-point type, e.g. float32 model outputs, without converting the
+// inputs first. See Regression.ObserveBatch.
+func ObserveRegressionBatch[A, P Float](m *Regression, actual []A, predicted []P, weights []float64) error {
+	if err := validateBatch(len(actual), weights, len(predicted)); err != nil {
+		return m.rejectBatch(len(actual), err)
 	}
 
-	m.resSum += residual * weight
-	m.resSum2 += residual * residual * weight
-	m.logSum2 += logres * logres * weight
-
-	m.sum += actual * weight
-	m.weight += weight
+	var berr BatchError
+	sh := m.shards.acquire()
+	for i := range actual {
+		act, pred, weight := float64(actual[i]), float64(predicted[i]), batchWeight(weights, i)
+		if err := validateNumerics(act, pred, weight); err != nil {
+			berr.add(i, m.reject(err))
+			continue
+		}
+		m.observe(&sh.state, act, pred, weight)
+	}
+	m.shards.release(sh)
+	return berr.errOrNil()
 }
 
 // TotalWeight returns the total weight observed.
 func (m *Regression) TotalWeight() float64 {
-	m.mu.RLock()
-	weight := m.weight
-	m.mu.RUnlock()
-	return weight
+	return m.merged().weight
 }
 
 // MaxError returns the maximum observed error delta.
 func (m *Regression) MaxError() float64 {
-	m.mu.RLock()
-	maxDelta := m.maxDelta
-	m.mu.RUnlock()
-
-	return maxDelta
+	return m.merged().maxDelta
 }
 
 // Mean returns the mean actual value observed.
 func (m *Regression) Mean() float64 {
-	m.mu.RLock()
-	weight := m.weight
-	sum := m.sum
-	m.mu.RUnlock()
-
-	if weight > 0 {
-		return sum / weight
-	}
-	return 0.0
+	return m.merged().mean()
 }
 
 // MAE calculates the mean absolute error.
 func (m *Regression) MAE() float64 {
-	m.mu.RLock()
-	weight := m.weight
-	resSum := m.resSum
-	m.mu.RUnlock()
-
-	if weight > 0 {
-		return resSum / weight
-	}
-	return 0.0
+	return m.merged().mae()
 }
 
 // MSE calculates the mean squared error.
 func (m *Regression) MSE() float64 {
-	m.mu.RLock()
-	weight := m.weight
-	resSum2 := m.resSum2
-	m.mu.RUnlock()
-
-	if weight > 0 {
-		return resSum2 / weight
-	}
-	return 0.0
+	return m.merged().mse()
 }
 
 // MSLE calculates the mean squared logarithmic error loss.
 func (m *Regression) MSLE() float64 {
-	m.mu.RLock()
-	weight := m.weight
-	logSum2 := m.logSum2
-	m.mu.RUnlock()
-
-	if weight > 0 {
-		return logSum2 / weight
-	}
-	return 0.0
+	return m.merged().msle()
 }
 
 // RMSE calculates the root mean squared error.
@@ -151,13 +158,303 @@ func (m *Regression) RMSLE() float64 {
 
 // R2 calculates the R² coefficient of determination.
 func (m *Regression) R2() float64 {
-	m.mu.RLock()
-	resSum2 := m.resSum2
-	totSum2 := m.totSum2
-	m.mu.RUnlock()
+	return m.merged().r2()
+}
+
+// ResidualMean returns the mean of signed residuals (actual - predicted). A
+// positive value indicates systematic under-prediction, a negative value
+// indicates systematic over-prediction.
+func (m *Regression) ResidualMean() float64 {
+	return m.merged().resMean
+}
+
+// ResidualVariance returns the variance of signed residuals.
+func (m *Regression) ResidualVariance() float64 {
+	return m.merged().residualVariance()
+}
+
+// ResidualSkewness returns the skewness of signed residuals.
+func (m *Regression) ResidualSkewness() float64 {
+	return m.merged().residualSkewness()
+}
+
+// ResidualKurtosis returns the excess kurtosis of signed residuals.
+func (m *Regression) ResidualKurtosis() float64 {
+	return m.merged().residualKurtosis()
+}
+
+// ResidualHistogram returns the histogram of signed residuals as upper bucket
+// bounds and the weights observed in each bucket. The weights contain an
+// additional trailing bucket for residuals above the highest bound. Returns nil
+// unless the metric was created via NewRegressionWithHistogram.
+func (m *Regression) ResidualHistogram() (buckets []float64, weights []float64) {
+	return m.histogram(m.merged())
+}
+
+// Snapshot returns a consistent point-in-time snapshot of all values.
+func (m *Regression) Snapshot() RegressionSnapshot {
+	snap := m.snapshot(m.merged())
+	snap.Rejected = m.Rejected()
+	return snap
+}
+
+// SnapshotAndReset atomically returns a snapshot of all values and resets state.
+func (m *Regression) SnapshotAndReset() RegressionSnapshot {
+	snap := m.snapshot(m.harvest())
+	snap.Rejected = m.harvestRejected()
+	return snap
+}
+
+// LinearBuckets creates count histogram buckets, each width wide, where the
+// lowest bucket has an upper bound of start.
+func LinearBuckets(start, width float64, count int) []float64 {
+	if count < 1 {
+		return nil
+	}
+
+	buckets := make([]float64, count)
+	for i := range buckets {
+		buckets[i] = start + float64(i)*width
+	}
+	return buckets
+}
+
+// ExponentialBuckets creates count histogram buckets, where the lowest bucket
+// has an upper bound of start and each following bucket's upper bound is factor
+// times the previous bucket's upper bound.
+func ExponentialBuckets(start, factor float64, count int) []float64 {
+	if count < 1 || start <= 0 || factor <= 1 {
+		return nil
+	}
+
+	buckets := make([]float64, count)
+	for i := range buckets {
+		buckets[i] = start
+		start *= factor
+	}
+	return buckets
+}
+
+// merged returns the merged state of all shards.
+func (m *Regression) merged() *regressionState {
+	st := new(regressionState)
+	m.shards.each(st.merge)
+	return st
+}
+
+// harvest returns the merged state of all shards and resets them.
+func (m *Regression) harvest() *regressionState {
+	st := new(regressionState)
+	m.shards.each(func(s *regressionState) {
+		st.merge(s)
+		*s = regressionState{}
+	})
+	return st
+}
+
+func (m *Regression) observe(st *regressionState, actual, predicted, weight float64) {
+	st.observe(actual, predicted, weight)
+	if m.buckets != nil {
+		if st.hist == nil {
+			st.hist = make([]float64, len(m.buckets)+1)
+		}
+		st.hist[sort.SearchFloat64s(m.buckets, actual-predicted)] += weight
+	}
+}
+
+func (m *Regression) histogram(st *regressionState) (buckets []float64, weights []float64) {
+	if m.buckets == nil {
+		return nil, nil
+	}
+
+	buckets = make([]float64, len(m.buckets))
+	copy(buckets, m.buckets)
+	weights = make([]float64, len(m.buckets)+1)
+	copy(weights, st.hist)
+	return
+}
+
+func (m *Regression) snapshot(st *regressionState) RegressionSnapshot {
+	s := st.snapshot()
+	s.HistogramBuckets, s.HistogramWeights = m.histogram(st)
+	return s
+}
 
-	if totSum2 > 0 {
-		return 1 - resSum2/totSum2
+type regressionState struct {
+	weight float64 // total weight observed
+	sum    float64 // sum of all values
+
+	resSum   float64 // residual sum
+	resSum2  float64 // residual sum of squares
+	logSum2  float64 // logarithmic residual sum of squares
+	totSum2  float64 // total sum of squares
+	maxDelta float64 // maximum error delta
+
+	resMean float64 // mean of signed residuals
+	resM2   float64 // second central moment sum of signed residuals
+	resM3   float64 // third central moment sum of signed residuals
+	resM4   float64 // fourth central moment sum of signed residuals
+
+	hist []float64 // histogram weights, allocated on demand
+}
+
+func (s *regressionState) observe(actual, predicted, weight float64) {
+	signed := actual - predicted
+	residual := math.Abs(signed)
+	logres := math.Abs(math.Log1p(actual) - math.Log1p(predicted))
+
+	if residual > s.maxDelta {
+		s.maxDelta = residual
+	}
+
+	s.resSum += residual * weight
+	s.resSum2 += residual * residual * weight
+	s.logSum2 += logres * logres * weight
+	s.mergeMoments(weight, signed, 0, 0, 0)
+
+	// weighted Welford update, consistent with merge
+	delta := actual - s.mean()
+	s.sum += actual * weight
+	s.weight += weight
+	s.totSum2 += weight * delta * (actual - s.sum/s.weight)
+}
+
+func (s *regressionState) merge(o *regressionState) {
+	if o.weight == 0 {
+		return
+	}
+
+	if o.maxDelta > s.maxDelta {
+		s.maxDelta = o.maxDelta
+	}
+	if s.weight != 0 {
+		delta := o.sum/o.weight - s.sum/s.weight
+		s.totSum2 += delta * delta * s.weight * o.weight / (s.weight + o.weight)
+	}
+	s.totSum2 += o.totSum2
+
+	s.resSum += o.resSum
+	s.resSum2 += o.resSum2
+	s.logSum2 += o.logSum2
+	s.mergeMoments(o.weight, o.resMean, o.resM2, o.resM3, o.resM4)
+
+	if o.hist != nil {
+		if s.hist == nil {
+			s.hist = make([]float64, len(o.hist))
+		}
+		for i, w := range o.hist {
+			s.hist[i] += w
+		}
+	}
+
+	s.sum += o.sum
+	s.weight += o.weight
+}
+
+// mergeMoments merges the moments of signed residuals with another set of weighted moments,
+// see https://en.wikipedia.org/wiki/Algorithms_for_calculating_variance#Higher-order_statistics
+func (s *regressionState) mergeMoments(weight, mean, m2, m3, m4 float64) {
+	total := s.weight + weight
+	if total <= 0 {
+		return
+	}
+
+	delta := mean - s.resMean
+	delta2 := delta * delta
+	ww := s.weight * weight
+
+	s.resM4 += m4 + delta2*delta2*ww*(s.weight*s.weight-ww+weight*weight)/(total*total*total) +
+		6*delta2*(s.weight*s.weight*m2+weight*weight*s.resM2)/(total*total) +
+		4*delta*(s.weight*m3-weight*s.resM3)/total
+	s.resM3 += m3 + delta2*delta*ww*(s.weight-weight)/(total*total) +
+		3*delta*(s.weight*m2-weight*s.resM2)/total
+	s.resM2 += m2 + delta2*ww/total
+	s.resMean += delta * weight / total
+}
+
+func (s *regressionState) mean() float64 {
+	if s.weight > 0 {
+		return s.sum / s.weight
+	}
+	return 0.0
+}
+
+func (s *regressionState) mae() float64 {
+	if s.weight > 0 {
+		return s.resSum / s.weight
+	}
+	return 0.0
+}
+
+func (s *regressionState) mse() float64 {
+	if s.weight > 0 {
+		return s.resSum2 / s.weight
 	}
 	return 0.0
 }
+
+func (s *regressionState) rmse() float64 {
+	return math.Sqrt(s.mse())
+}
+
+func (s *regressionState) msle() float64 {
+	if s.weight > 0 {
+		return s.logSum2 / s.weight
+	}
+	return 0.0
+}
+
+func (s *regressionState) r2() float64 {
+	if s.totSum2 > 0 {
+		return 1 - s.resSum2/s.totSum2
+	}
+	return 0.0
+}
+
+// variance returns the variance of observed actual values.
+func (s *regressionState) variance() float64 {
+	if s.weight > 0 {
+		return s.totSum2 / s.weight
+	}
+	return 0.0
+}
+
+func (s *regressionState) residualVariance() float64 {
+	if s.weight > 0 {
+		return s.resM2 / s.weight
+	}
+	return 0.0
+}
+
+func (s *regressionState) residualSkewness() float64 {
+	if s.resM2 > 0 {
+		return math.Sqrt(s.weight) * s.resM3 / math.Pow(s.resM2, 1.5)
+	}
+	return 0.0
+}
+
+func (s *regressionState) residualKurtosis() float64 {
+	if s.resM2 > 0 {
+		return s.weight*s.resM4/(s.resM2*s.resM2) - 3
+	}
+	return 0.0
+}
+
+func (s *regressionState) snapshot() RegressionSnapshot {
+	return RegressionSnapshot{
+		Weight:   s.weight,
+		Mean:     s.mean(),
+		MAE:      s.mae(),
+		MSE:      s.mse(),
+		RMSE:     s.rmse(),
+		MSLE:     s.msle(),
+		RMSLE:    math.Sqrt(s.msle()),
+		R2:       s.r2(),
+		MaxError: s.maxDelta,
+
+		ResidualMean:     s.resMean,
+		ResidualVariance: s.residualVariance(),
+		ResidualSkewness: s.residualSkewness(),
+		ResidualKurtosis: s.residualKurtosis(),
+	}
+}
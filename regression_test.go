@@ -3,6 +3,7 @@ package mlmetrics_test
 import (
 	"fmt"
 	"math"
+	"testing"
 
 	. "github.com/bsm/ginkgo"
 	. "github.com/bsm/gomega"
@@ -41,10 +42,45 @@ var _ = Describe("Regression", func() {
 	})
 
 	It("should calculate R²", func() {
-		Expect(subject.R2()).To(BeNumerically("~", 0.390, 0.01))
+		Expect(subject.R2()).To(BeNumerically("~", 0.178, 0.001))
 
 		subject.ObserveWeight(28, 28, 2.0)
-		Expect(subject.R2()).To(BeNumerically("~", 0.477, 0.001))
+		Expect(subject.R2()).To(BeNumerically("~", 0.305, 0.001))
+	})
+
+	It("should calculate residual distribution", func() {
+		Expect(subject.ResidualMean()).To(BeNumerically("~", -0.273, 0.001))
+		Expect(subject.ResidualVariance()).To(BeNumerically("~", 6.926, 0.001))
+		Expect(subject.ResidualSkewness()).To(BeNumerically("~", -0.242, 0.001))
+		Expect(subject.ResidualKurtosis()).To(BeNumerically("~", -0.881, 0.001))
+
+		buckets, weights := subject.ResidualHistogram()
+		Expect(buckets).To(BeNil())
+		Expect(weights).To(BeNil())
+	})
+
+	It("should calculate residual histograms", func() {
+		subject = mlmetrics.NewRegressionWithHistogram(mlmetrics.LinearBuckets(-4, 2, 5))
+		for _, r := range []float64{1, -5, 2, -2, -1, -4, -1, 2, 2, -1, 4} {
+			subject.Observe(20+r, 20)
+		}
+
+		buckets, weights := subject.ResidualHistogram()
+		Expect(buckets).To(Equal([]float64{-4, -2, 0, 2, 4}))
+		Expect(weights).To(Equal([]float64{2, 1, 3, 4, 1, 0}))
+		Expect(subject.ResidualMean()).To(BeNumerically("~", -0.273, 0.001))
+
+		subject.Reset()
+		buckets, weights = subject.ResidualHistogram()
+		Expect(buckets).To(HaveLen(5))
+		Expect(weights).To(Equal([]float64{0, 0, 0, 0, 0, 0}))
+	})
+
+	It("should generate buckets", func() {
+		Expect(mlmetrics.LinearBuckets(-1, 0.5, 5)).To(Equal([]float64{-1, -0.5, 0, 0.5, 1}))
+		Expect(mlmetrics.LinearBuckets(0, 1, 0)).To(BeNil())
+		Expect(mlmetrics.ExponentialBuckets(1, 2, 4)).To(Equal([]float64{1, 2, 4, 8}))
+		Expect(mlmetrics.ExponentialBuckets(0, 2, 4)).To(BeNil())
 	})
 
 	It("should handle blanks", func() {
@@ -57,6 +93,10 @@ var _ = Describe("Regression", func() {
 		Expect(subject.RMSE()).To(Equal(0.0))
 		Expect(subject.MSLE()).To(Equal(0.0))
 		Expect(subject.RMSLE()).To(Equal(0.0))
+		Expect(subject.ResidualMean()).To(Equal(0.0))
+		Expect(subject.ResidualVariance()).To(Equal(0.0))
+		Expect(subject.ResidualSkewness()).To(Equal(0.0))
+		Expect(subject.ResidualKurtosis()).To(Equal(0.0))
 	})
 
 	It("should handle negative values", func() {
@@ -70,6 +110,97 @@ var _ = Describe("Regression", func() {
 		Expect(math.IsNaN(subject.MSLE())).To(BeTrue())
 		Expect(math.IsNaN(subject.RMSLE())).To(BeTrue())
 	})
+
+	It("should snapshot", func() {
+		snap := subject.Snapshot()
+		Expect(snap).To(Equal(mlmetrics.RegressionSnapshot{
+			Weight:   subject.TotalWeight(),
+			Mean:     subject.Mean(),
+			MAE:      subject.MAE(),
+			MSE:      subject.MSE(),
+			RMSE:     subject.RMSE(),
+			MSLE:     subject.MSLE(),
+			RMSLE:    subject.RMSLE(),
+			R2:       subject.R2(),
+			MaxError: subject.MaxError(),
+
+			ResidualMean:     subject.ResidualMean(),
+			ResidualVariance: subject.ResidualVariance(),
+			ResidualSkewness: subject.ResidualSkewness(),
+			ResidualKurtosis: subject.ResidualKurtosis(),
+		}))
+
+		subject.Observe(100, 0)
+		Expect(snap.MaxError).NotTo(Equal(subject.MaxError()))
+
+		subject = mlmetrics.NewRegressionWithHistogram([]float64{0})
+		subject.Observe(2, 1)
+		snap = subject.Snapshot()
+		Expect(snap.HistogramBuckets).To(Equal([]float64{0}))
+		Expect(snap.HistogramWeights).To(Equal([]float64{0, 1}))
+	})
+
+	It("should merge concurrent observations", func() {
+		buckets := mlmetrics.LinearBuckets(-4, 2, 5)
+		expected := mlmetrics.NewRegressionWithHistogram(buckets)
+		for i := 0; i < 1000; i++ {
+			expected.Observe(float64(i%17), float64(i%13))
+		}
+
+		subject = mlmetrics.NewRegressionWithHistogram(buckets)
+		observeConcurrently(1000, func(i int) {
+			subject.Observe(float64(i%17), float64(i%13))
+		})
+
+		snap, exp := subject.Snapshot(), expected.Snapshot()
+		Expect(snap.Weight).To(Equal(exp.Weight))
+		Expect(snap.MaxError).To(Equal(exp.MaxError))
+		Expect(snap.HistogramWeights).To(Equal(exp.HistogramWeights))
+		Expect(snap.Mean).To(BeNumerically("~", exp.Mean, 1e-9))
+		Expect(snap.MAE).To(BeNumerically("~", exp.MAE, 1e-9))
+		Expect(snap.MSE).To(BeNumerically("~", exp.MSE, 1e-9))
+		Expect(snap.MSLE).To(BeNumerically("~", exp.MSLE, 1e-9))
+		Expect(snap.R2).To(BeNumerically("~", exp.R2, 1e-9))
+		Expect(snap.ResidualMean).To(BeNumerically("~", exp.ResidualMean, 1e-9))
+		Expect(snap.ResidualVariance).To(BeNumerically("~", exp.ResidualVariance, 1e-9))
+		Expect(snap.ResidualSkewness).To(BeNumerically("~", exp.ResidualSkewness, 1e-9))
+		Expect(snap.ResidualKurtosis).To(BeNumerically("~", exp.ResidualKurtosis, 1e-9))
+	})
+
+	It("should observe batches", func() {
+		expected := mlmetrics.NewRegressionWithHistogram([]float64{0})
+		expected.Observe(26, 25)
+		expected.Observe(20, 25)
+		expected.ObserveWeight(28, 26, 2.0)
+
+		subject = mlmetrics.NewRegressionWithHistogram([]float64{0})
+		Expect(subject.ObserveBatch([]float64{26, 20, math.NaN(), 28}, []float64{25, 25, 1, 26}, []float64{1, 1, 1, 2})).To(MatchError(mlmetrics.ErrInvalidNumeric))
+		Expect(subject.Snapshot()).To(equalSnapshot(expected.Snapshot()))
+		Expect(subject.ObserveBatch([]float64{1, 2}, []float64{1}, nil)).To(MatchError(mlmetrics.ErrLengthMismatch))
+	})
+
+	It("should observe float32 batches", func() {
+		expected := mlmetrics.NewRegression()
+		Expect(expected.ObserveBatch([]float64{26, 20, 28}, []float64{25.5, 25, 26}, []float64{1, 1, 2})).To(Succeed())
+
+		subject = mlmetrics.NewRegression()
+		Expect(mlmetrics.ObserveRegressionBatch(subject, []float64{26, 20, 1, 28}, []float32{25.5, 25, float32(math.NaN()), 26}, []float64{1, 1, 1, 2})).To(MatchError(mlmetrics.ErrInvalidNumeric))
+		Expect(subject.Snapshot()).To(equalSnapshot(expected.Snapshot()))
+		Expect(subject.Rejected()).To(Equal(mlmetrics.Rejections{InvalidNumeric: 1}))
+	})
+
+	It("should reject invalid values", func() {
+		subject.Observe(math.NaN(), 1)
+		subject.Observe(1, math.NaN())
+		subject.ObserveWeight(1, 1, 0)
+		Expect(subject.TotalWeight()).To(Equal(11.0))
+		Expect(subject.Rejected()).To(Equal(mlmetrics.Rejections{InvalidNumeric: 2, InvalidWeight: 1}))
+
+		Expect(subject.ObserveWeightStrict(1, math.NaN(), 1)).To(MatchError(mlmetrics.ErrInvalidNumeric))
+		Expect(subject.ObserveBatch([]float64{1, 2, math.NaN()}, []float64{1, 2, 3}, nil)).To(MatchError(&mlmetrics.BatchError{Index: 2, Err: mlmetrics.ErrInvalidNumeric, Rejected: 1}))
+		Expect(subject.TotalWeight()).To(Equal(13.0))
+		Expect(subject.Rejected().InvalidNumeric).To(Equal(int64(4)))
+	})
 })
 
 func ExampleRegression() {
@@ -97,5 +228,47 @@ func ExampleRegression() {
 	// rmse  : 2.726
 	// msle  : 0.012
 	// rmsle : 0.110
-	// r2    : 0.162
+	// r2    : -0.319
+}
+
+func BenchmarkRegression_ObserveParallel(b *testing.B) {
+	m := mlmetrics.NewRegression()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			m.Observe(float64(i%17), float64(i%13))
+		}
+	})
+}
+
+func BenchmarkRegression_ObserveBatch(b *testing.B) {
+	m := mlmetrics.NewRegression()
+	actual := make([]float64, 1000)
+	predicted := make([]float64, 1000)
+	for i := range actual {
+		actual[i], predicted[i] = float64(i%17), float64(i%13)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := m.ObserveBatch(actual, predicted, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRegression_ObserveBatchFloat32(b *testing.B) {
+	m := mlmetrics.NewRegression()
+	actual := make([]float32, 1000)
+	predicted := make([]float32, 1000)
+	for i := range actual {
+		actual[i], predicted[i] = float32(i%17), float32(i%13)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := mlmetrics.ObserveRegressionBatch(m, actual, predicted, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
 }
@@ -0,0 +1,167 @@
+package mlmetrics
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// Errors returned by ObserveWeightStrict, when observations are rejected by validation.
+var (
+	ErrInvalidCategory    = errors.New("mlmetrics: invalid category")
+	ErrInvalidProbability = errors.New("mlmetrics: invalid probability")
+	ErrInvalidWeight      = errors.New("mlmetrics: invalid weight")
+	ErrInvalidNumeric     = errors.New("mlmetrics: invalid numeric value")
+	ErrInvalidInterval    = errors.New("mlmetrics: invalid interval")
+	ErrOutOfDomain        = errors.New("mlmetrics: value outside the valid domain")
+)
+
+// Rejections contains the numbers of observations rejected by validation, per reason.
+type Rejections struct {
+	InvalidCategory    int64 // negative categories, labels or items
+	InvalidProbability int64 // probabilities outside [0, 1]
+	InvalidWeight      int64 // weights which are not positive
+	InvalidNumeric     int64 // NaN values, or infinite values where unsupported
+	InvalidInterval    int64 // intervals with a lower bound above the upper bound
+	LengthMismatch     int64 // mismatching numbers of actual and predicted values or relevant items
+	OutOfDomain        int64 // values outside the valid domain of a distribution
+}
+
+// Total returns the total number of rejected observations.
+func (r Rejections) Total() int64 {
+	return r.InvalidCategory + r.InvalidProbability + r.InvalidWeight + r.InvalidNumeric + r.InvalidInterval + r.LengthMismatch + r.OutOfDomain
+}
+
+// BatchError is returned by ObserveBatch if observations of a batch were rejected. All valid observations of the batch are recorded regardless.
+type BatchError struct {
+	Index    int   // index of the first rejected observation
+	Err      error // reason the first observation was rejected
+	Rejected int   // number of rejected observations
+}
+
+// Error implements the error interface.
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("%s (observation %d, %d rejected)", e.Err, e.Index, e.Rejected)
+}
+
+// Unwrap returns the reason the first observation was rejected.
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// add records a rejection of observation i, nil errors are ignored.
+func (e *BatchError) add(i int, err error) {
+	if err == nil {
+		return
+	}
+	if e.Rejected == 0 {
+		e.Index, e.Err = i, err
+	}
+	e.Rejected++
+}
+
+// errOrNil returns the batch error if any observations were rejected.
+func (e *BatchError) errOrNil() error {
+	if e.Rejected == 0 {
+		return nil
+	}
+
+	err := *e // only escape to the heap if observations were rejected
+	return &err
+}
+
+// rejector counts observations rejected by validation, it is embedded in metrics.
+type rejector struct {
+	counts [7]int64
+}
+
+// Rejected returns the numbers of observations rejected by validation since the
+// metric was created or last reset by Reset or SnapshotAndReset.
+func (r *rejector) Rejected() Rejections {
+	return r.rejections(func(n *int64) int64 { return atomic.LoadInt64(n) })
+}
+
+// harvestRejected returns the rejection counts and resets them.
+func (r *rejector) harvestRejected() Rejections {
+	return r.rejections(func(n *int64) int64 { return atomic.SwapInt64(n, 0) })
+}
+
+// rejections reads all rejection counts with the given atomic operation.
+func (r *rejector) rejections(read func(*int64) int64) Rejections {
+	return Rejections{
+		InvalidCategory:    read(&r.counts[0]),
+		InvalidProbability: read(&r.counts[1]),
+		InvalidWeight:      read(&r.counts[2]),
+		InvalidNumeric:     read(&r.counts[3]),
+		InvalidInterval:    read(&r.counts[4]),
+		LengthMismatch:     read(&r.counts[5]),
+		OutOfDomain:        read(&r.counts[6]),
+	}
+}
+
+// reject counts a rejected observation and returns err. It panics if err is not one
+// of the validation errors.
+func (r *rejector) reject(err error) error {
+	var n int
+	switch err {
+	case ErrInvalidCategory:
+		n = 0
+	case ErrInvalidProbability:
+		n = 1
+	case ErrInvalidWeight:
+		n = 2
+	case ErrInvalidNumeric:
+		n = 3
+	case ErrInvalidInterval:
+		n = 4
+	case ErrLengthMismatch:
+		n = 5
+	case ErrOutOfDomain:
+		n = 6
+	default:
+		panic("mlmetrics: unexpected rejection " + err.Error())
+	}
+	atomic.AddInt64(&r.counts[n], 1)
+	return err
+}
+
+// rejectBatch counts the n observations of a batch dropped because the lengths of
+// its inputs differ and returns err.
+func (r *rejector) rejectBatch(n int, err error) error {
+	if n < 1 {
+		n = 1
+	}
+	atomic.AddInt64(&r.counts[5], int64(n))
+	return err
+}
+
+// resetRejected resets the rejection counts.
+func (r *rejector) resetRejected() {
+	for i := range r.counts {
+		atomic.StoreInt64(&r.counts[i], 0)
+	}
+}
+
+// validateCategories validates an observation of actual vs predicted categories.
+func validateCategories(actual, predicted int, weight float64) error {
+	if !isValidCategory(actual) || !isValidCategory(predicted) {
+		return ErrInvalidCategory
+	}
+	return validateWeight(weight)
+}
+
+// validateNumerics validates an observation of actual vs predicted values.
+func validateNumerics(actual, predicted, weight float64) error {
+	if !isValidNumeric(actual) || !isValidNumeric(predicted) {
+		return ErrInvalidNumeric
+	}
+	return validateWeight(weight)
+}
+
+// validateWeight validates the weight of an observation.
+func validateWeight(weight float64) error {
+	if !isValidWeight(weight) {
+		return ErrInvalidWeight
+	}
+	return nil
+}
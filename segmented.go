@@ -0,0 +1,146 @@
+package mlmetrics
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// Segmented evaluates a metric separately per segment key (e.g. country, device or
+// model version). Metrics are created lazily, the first time a key is seen.
+type Segmented[M any] struct {
+	factory  func() M
+	segments map[string]M
+	mu       sync.RWMutex
+}
+
+// Segment is a single segment of a Segmented metric.
+type Segment[M any] struct {
+	Key    string  // segment key
+	Metric M       // segment metric
+	Loss   float64 // loss as calculated by the loss function passed to Worst
+}
+
+// NewSegmented inits a new segmented metric. The factory is called to create a
+// metric for each new segment, e.g.
+//
+//	mlmetrics.NewSegmented(mlmetrics.NewAccuracy)
+func NewSegmented[M any](factory func() M) *Segmented[M] {
+	return &Segmented[M]{factory: factory, segments: make(map[string]M)}
+}
+
+// Reset removes all segments.
+func (s *Segmented[M]) Reset() {
+	s.mu.Lock()
+	s.segments = make(map[string]M)
+	s.mu.Unlock()
+}
+
+// In returns the metric of the segment identified by key, creating it if necessary.
+func (s *Segmented[M]) In(key string) M {
+	s.mu.RLock()
+	m, ok := s.segments[key]
+	s.mu.RUnlock()
+	if ok {
+		return m
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if m, ok = s.segments[key]; !ok {
+		m = s.factory()
+		s.segments[key] = m
+	}
+	return m
+}
+
+// ObserveIn passes the metric of the segment identified by key to fn, creating it if
+// necessary, e.g.
+//
+//	s.ObserveIn("de", func(m *mlmetrics.Accuracy) { m.Observe(1, 1) })
+func (s *Segmented[M]) ObserveIn(key string, fn func(M)) {
+	fn(s.In(key))
+}
+
+// Get returns the metric of an existing segment.
+func (s *Segmented[M]) Get(key string) (M, bool) {
+	s.mu.RLock()
+	m, ok := s.segments[key]
+	s.mu.RUnlock()
+	return m, ok
+}
+
+// Len returns the number of segments.
+func (s *Segmented[M]) Len() int {
+	s.mu.RLock()
+	n := len(s.segments)
+	s.mu.RUnlock()
+	return n
+}
+
+// Keys returns the sorted keys of all segments.
+func (s *Segmented[M]) Keys() []string {
+	s.mu.RLock()
+	keys := make([]string, 0, len(s.segments))
+	for key := range s.segments {
+		keys = append(keys, key)
+	}
+	s.mu.RUnlock()
+
+	sort.Strings(keys)
+	return keys
+}
+
+// Worst returns up to n segments with the highest loss, ordered by descending loss.
+// The loss function is called for every segment, higher values indicate worse
+// performance, e.g.
+//
+//	s.Worst(3, func(m *mlmetrics.Accuracy) float64 { return 1 - m.Rate() })
+//
+// Segments with a NaN loss are excluded, which can be used to skip segments with too
+// few observations. If n is not positive, all segments are returned.
+func (s *Segmented[M]) Worst(n int, loss func(M) float64) []Segment[M] {
+	s.mu.RLock()
+	segments := make([]Segment[M], 0, len(s.segments))
+	for key, m := range s.segments {
+		segments = append(segments, Segment[M]{Key: key, Metric: m})
+	}
+	s.mu.RUnlock()
+
+	res := segments[:0]
+	for _, seg := range segments {
+		if seg.Loss = loss(seg.Metric); !math.IsNaN(seg.Loss) {
+			res = append(res, seg)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Loss != res[j].Loss {
+			return res[i].Loss > res[j].Loss
+		}
+		return res[i].Key < res[j].Key
+	})
+
+	if n > 0 && n < len(res) {
+		res = res[:n]
+	}
+	return res
+}
+
+// segmentViews implements segmentedMetric.
+func (s *Segmented[M]) segmentViews() map[string]interface{} {
+	s.mu.RLock()
+	views := make(map[string]interface{}, len(s.segments))
+	for key, m := range s.segments {
+		views[key] = m
+	}
+	s.mu.RUnlock()
+	return views
+}
+
+// zeroMetric implements segmentedMetric.
+func (s *Segmented[M]) zeroMetric() interface{} {
+	var m M
+	return m
+}
@@ -0,0 +1,94 @@
+package mlmetrics_test
+
+import (
+	"math"
+	"sync"
+
+	. "github.com/bsm/ginkgo"
+	. "github.com/bsm/gomega"
+	"github.com/bsm/mlmetrics"
+)
+
+var _ = Describe("Segmented", func() {
+	var subject *mlmetrics.Segmented[*mlmetrics.Accuracy]
+
+	accuracyLoss := func(m *mlmetrics.Accuracy) float64 {
+		if m.TotalWeight() < 2 {
+			return math.NaN()
+		}
+		return 1 - m.Rate()
+	}
+
+	BeforeEach(func() {
+		subject = mlmetrics.NewSegmented(mlmetrics.NewAccuracy)
+		subject.In("de").Observe(1, 1)
+		subject.In("de").Observe(1, 0)
+		subject.In("fr").Observe(1, 1)
+		subject.In("fr").Observe(0, 0)
+		subject.ObserveIn("uk", func(m *mlmetrics.Accuracy) { m.Observe(1, 0) })
+		subject.ObserveIn("uk", func(m *mlmetrics.Accuracy) { m.Observe(0, 0) })
+		subject.ObserveIn("us", func(m *mlmetrics.Accuracy) { m.Observe(0, 1) })
+	})
+
+	It("should create segments lazily", func() {
+		Expect(subject.Len()).To(Equal(4))
+		Expect(subject.Keys()).To(Equal([]string{"de", "fr", "uk", "us"}))
+
+		m, ok := subject.Get("de")
+		Expect(ok).To(BeTrue())
+		Expect(m.TotalWeight()).To(Equal(2.0))
+		Expect(m.Rate()).To(Equal(0.5))
+
+		_, ok = subject.Get("it")
+		Expect(ok).To(BeFalse())
+		Expect(subject.Len()).To(Equal(4))
+	})
+
+	It("should return worst segments", func() {
+		worst := subject.Worst(2, accuracyLoss)
+		Expect(worst).To(HaveLen(2))
+		Expect(worst[0].Key).To(Equal("de"))
+		Expect(worst[0].Loss).To(Equal(0.5))
+		Expect(worst[1].Key).To(Equal("uk"))
+		Expect(worst[1].Loss).To(Equal(0.5))
+
+		all := subject.Worst(0, accuracyLoss)
+		Expect(all).To(HaveLen(3))
+		Expect(all[2].Key).To(Equal("fr"))
+		Expect(all[2].Metric.Rate()).To(Equal(1.0))
+	})
+
+	It("should reset", func() {
+		subject.Reset()
+		Expect(subject.Len()).To(Equal(0))
+		Expect(subject.Worst(0, accuracyLoss)).To(BeEmpty())
+	})
+
+	It("should support other metrics", func() {
+		regression := mlmetrics.NewSegmented(mlmetrics.NewRegression)
+		regression.In("v1").Observe(1, 2)
+		regression.In("v2").Observe(1, 4)
+
+		worst := regression.Worst(1, (*mlmetrics.Regression).RMSE)
+		Expect(worst).To(HaveLen(1))
+		Expect(worst[0].Key).To(Equal("v2"))
+		Expect(worst[0].Loss).To(Equal(3.0))
+	})
+
+	It("should be thread-safe", func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					subject.In("it").Observe(1, 1)
+				}
+			}()
+		}
+		wg.Wait()
+
+		m, _ := subject.Get("it")
+		Expect(m.TotalWeight()).To(Equal(800.0))
+	})
+})
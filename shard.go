@@ -0,0 +1,73 @@
+package mlmetrics
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// shards distributes the state of a metric across multiple, separately locked
+// shards to reduce lock contention between concurrent observers. Observers record
+// into a single shard, which is usually local to the current processor, readers
+// merge the states of all shards.
+//
+// The zero value is ready to use and must not be copied after first use.
+type shards[S any] struct {
+	list []*shard[S]
+	pool sync.Pool
+	next uint32
+	once sync.Once
+}
+
+// shard is a single shard, padded to prevent false sharing between processors.
+type shard[S any] struct {
+	mu    sync.Mutex
+	state S
+	_     [64]byte
+}
+
+// acquire returns a locked shard, observers must call release when done.
+func (s *shards[S]) acquire() *shard[S] {
+	s.once.Do(s.init)
+
+	sh := s.pool.Get().(*shard[S])
+	sh.mu.Lock()
+	return sh
+}
+
+// release unlocks a shard returned by acquire.
+func (s *shards[S]) release(sh *shard[S]) {
+	sh.mu.Unlock()
+	s.pool.Put(sh)
+}
+
+// each calls fn with the state of each shard. It holds the locks of all shards
+// until done, so readers see a consistent point-in-time view across shards.
+// Locks are always acquired in the same order, observers hold at most one.
+func (s *shards[S]) each(fn func(*S)) {
+	s.once.Do(s.init)
+
+	for _, sh := range s.list {
+		sh.mu.Lock()
+	}
+	for _, sh := range s.list {
+		fn(&sh.state)
+	}
+	for _, sh := range s.list {
+		sh.mu.Unlock()
+	}
+}
+
+func (s *shards[S]) init() {
+	s.list = make([]*shard[S], runtime.GOMAXPROCS(0))
+	for i := range s.list {
+		s.list[i] = new(shard[S])
+	}
+
+	// the pool hands out shards round-robin, processors typically keep reusing
+	// the same shard from their local pool cache
+	s.pool.New = func() interface{} {
+		n := atomic.AddUint32(&s.next, 1)
+		return s.list[int(n-1)%len(s.list)]
+	}
+}
@@ -0,0 +1,112 @@
+package mlmetrics
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Windowed evaluates a metric in consecutive time windows of fixed size (e.g. one
+// metric per hour) and retains a limited number of recent windows. Metrics are
+// created lazily, the first time a window is observed.
+type Windowed[M any] struct {
+	factory func() M
+	size    time.Duration
+	keep    int
+	windows []Window[M] // ordered by start time
+
+	mu sync.Mutex
+}
+
+// Window is a single time window of a Windowed metric.
+type Window[M any] struct {
+	Start  time.Time // start of the window, inclusive
+	End    time.Time // end of the window, exclusive
+	Metric M         // window metric
+}
+
+// NewWindowed inits a new windowed metric with a window size and the number of
+// windows to retain. The factory is called to create a metric for each new window.
+// Defaults: 1h windows, retain 24.
+func NewWindowed[M any](factory func() M, size time.Duration, keep int) *Windowed[M] {
+	if size <= 0 {
+		size = time.Hour
+	}
+	if keep < 1 {
+		keep = 24
+	}
+	return &Windowed[M]{factory: factory, size: size, keep: keep}
+}
+
+// Size returns the window size.
+func (w *Windowed[M]) Size() time.Duration {
+	return w.size
+}
+
+// Reset removes all windows.
+func (w *Windowed[M]) Reset() {
+	w.mu.Lock()
+	w.windows = nil
+	w.mu.Unlock()
+}
+
+// Current returns the metric of the current window.
+func (w *Windowed[M]) Current() M {
+	return w.At(time.Now())
+}
+
+// At returns the metric of the window containing time t, creating it if necessary.
+// Windows that are older than the retained windows are not stored, observations
+// recorded in their metrics are discarded.
+func (w *Windowed[M]) At(t time.Time) M {
+	start := t.Truncate(w.size)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	pos := sort.Search(len(w.windows), func(i int) bool { return !w.windows[i].Start.Before(start) })
+	if pos < len(w.windows) && w.windows[pos].Start.Equal(start) {
+		return w.windows[pos].Metric
+	}
+
+	win := Window[M]{Start: start, End: start.Add(w.size), Metric: w.factory()}
+	w.windows = append(w.windows, Window[M]{})
+	copy(w.windows[pos+1:], w.windows[pos:])
+	w.windows[pos] = win
+
+	// drop windows outside of the retention period
+	cutoff := w.windows[len(w.windows)-1].Start.Add(-time.Duration(w.keep-1) * w.size)
+	n := 0
+	for n < len(w.windows) && w.windows[n].Start.Before(cutoff) {
+		n++
+	}
+	if n != 0 {
+		w.windows = append(w.windows[:0], w.windows[n:]...)
+	}
+	return win.Metric
+}
+
+// Windows returns the retained windows, ordered by start time.
+func (w *Windowed[M]) Windows() []Window[M] {
+	w.mu.Lock()
+	windows := make([]Window[M], len(w.windows))
+	copy(windows, w.windows)
+	w.mu.Unlock()
+	return windows
+}
+
+// windowViews implements windowedMetric.
+func (w *Windowed[M]) windowViews() []Window[interface{}] {
+	windows := w.Windows()
+	views := make([]Window[interface{}], len(windows))
+	for i, win := range windows {
+		views[i] = Window[interface{}]{Start: win.Start, End: win.End, Metric: win.Metric}
+	}
+	return views
+}
+
+// zeroMetric implements windowedMetric.
+func (w *Windowed[M]) zeroMetric() interface{} {
+	var m M
+	return m
+}
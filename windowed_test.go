@@ -0,0 +1,58 @@
+package mlmetrics_test
+
+import (
+	"time"
+
+	. "github.com/bsm/ginkgo"
+	. "github.com/bsm/gomega"
+	"github.com/bsm/mlmetrics"
+)
+
+var _ = Describe("Windowed", func() {
+	var subject *mlmetrics.Windowed[*mlmetrics.Accuracy]
+
+	epoch := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	BeforeEach(func() {
+		subject = mlmetrics.NewWindowed(mlmetrics.NewAccuracy, time.Hour, 3)
+		subject.At(epoch.Add(10*time.Minute)).Observe(1, 1)
+		subject.At(epoch.Add(70*time.Minute)).Observe(1, 0)
+		subject.At(epoch.Add(80*time.Minute)).Observe(1, 1)
+	})
+
+	It("should observe in windows", func() {
+		Expect(subject.Size()).To(Equal(time.Hour))
+
+		windows := subject.Windows()
+		Expect(windows).To(HaveLen(2))
+		Expect(windows[0].Start).To(Equal(epoch))
+		Expect(windows[0].End).To(Equal(epoch.Add(time.Hour)))
+		Expect(windows[0].Metric.Rate()).To(Equal(1.0))
+		Expect(windows[1].Start).To(Equal(epoch.Add(time.Hour)))
+		Expect(windows[1].Metric.Rate()).To(Equal(0.5))
+	})
+
+	It("should retain recent windows", func() {
+		subject.At(epoch.Add(3*time.Hour)).Observe(0, 0)
+		windows := subject.Windows()
+		Expect(windows).To(HaveLen(2))
+		Expect(windows[0].Start).To(Equal(epoch.Add(time.Hour)))
+		Expect(windows[1].Start).To(Equal(epoch.Add(3 * time.Hour)))
+
+		// insert missing window
+		subject.At(epoch.Add(2*time.Hour)).Observe(0, 0)
+		Expect(subject.Windows()).To(HaveLen(3))
+
+		// discard outdated
+		subject.At(epoch).Observe(0, 0)
+		Expect(subject.Windows()).To(HaveLen(3))
+		Expect(subject.Windows()[0].Start).To(Equal(epoch.Add(time.Hour)))
+	})
+
+	It("should reset", func() {
+		subject.Reset()
+		Expect(subject.Windows()).To(BeEmpty())
+		Expect(subject.Current().TotalWeight()).To(Equal(0.0))
+		Expect(subject.Windows()).To(HaveLen(1))
+	})
+})